@@ -0,0 +1,630 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LetsEncryptURL is the production directory URL of Let's Encrypt.
+const LetsEncryptURL = "https://acme-v02.api.letsencrypt.org/directory"
+
+// ACME challenge types.
+const (
+	ChallengeHTTP01    = "http-01"
+	ChallengeTLSALPN01 = "tls-alpn-01"
+)
+
+const acmeALPNProto = "acme-tls/1"
+
+// id-pe-acmeIdentifier, see RFC 8737.
+var oidACMEIdentifier = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+// ACMEConfig configures automatic certificates via ACME (RFC 8555).
+type ACMEConfig struct {
+	// DirectoryURL of the ACME server. Default is [LetsEncryptURL].
+	// Set to a local ACME server (like Pebble) for tests.
+	DirectoryURL string
+
+	// Email used for the account registration. Optional.
+	Email string
+
+	// Hosts allowed to obtain certificates for. Required.
+	Hosts []string
+
+	// Cache for the account key and certificates. Default is [MemoryCache].
+	// Use [DirCache] or a shared storage to survive restarts.
+	Cache CertCache
+
+	// RenewBefore is how long before expiration certificates are renewed. Default is 30 days.
+	RenewBefore time.Duration
+
+	// Challenges to use in order of preference.
+	// Default is tls-alpn-01 then http-01. http-01 requires [ServerConfig.RedirectAddr] on port 80.
+	Challenges []string
+
+	// KeyType of certificate keys. Default is ECDSA.
+	KeyType KeyType
+
+	// HTTPClient to talk to the ACME server. Default is [NewPooledClient] with 30 seconds timeout.
+	HTTPClient *http.Client
+}
+
+// CertCache stores ACME account key and certificates.
+type CertCache interface {
+	// Get returns data by key or [ErrCacheMiss].
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, data []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrCacheMiss is returned by [CertCache] when a key is not found.
+var ErrCacheMiss = errors.New("httpx: cache miss")
+
+// DirCache is [CertCache] which stores data as files in a directory.
+type DirCache string
+
+// Get implements [CertCache].
+func (d DirCache) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(string(d), key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCacheMiss
+	}
+	return data, err
+}
+
+// Put implements [CertCache].
+func (d DirCache) Put(ctx context.Context, key string, data []byte) error {
+	if err := os.MkdirAll(string(d), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(string(d), key), data, 0o600)
+}
+
+// Delete implements [CertCache].
+func (d DirCache) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(string(d), key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// MemoryCache is in-memory [CertCache]. Zero value is ready to use.
+type MemoryCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// Get implements [CertCache].
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, ok := c.data[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return data, nil
+}
+
+// Put implements [CertCache].
+func (c *MemoryCache) Put(ctx context.Context, key string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.data == nil {
+		c.data = map[string][]byte{}
+	}
+	c.data[key] = data
+	return nil
+}
+
+// Delete implements [CertCache].
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.data, key)
+	return nil
+}
+
+// ACMEManager obtains and renews certificates via ACME.
+// Certificates are served by [ACMEManager.GetCertificate].
+type ACMEManager struct {
+	cfg    ACMEConfig
+	logger *slog.Logger
+
+	clientMu sync.Mutex
+	client   *acmeClient
+
+	mu         sync.Mutex
+	certs      map[string]*tls.Certificate
+	inflight   map[string]*acmeCall
+	retryAfter map[string]time.Time // backoff for background renewals.
+	tokens     map[string]string    // http-01 token -> key authorization.
+	alpnCerts  map[string]*tls.Certificate
+}
+
+type acmeCall struct {
+	done chan struct{}
+	cert *tls.Certificate
+	err  error
+}
+
+const acmeAccountKey = "+acme_account_key"
+
+// NewACMEManager returns a new ACMEManager.
+func NewACMEManager(cfg ACMEConfig) (*ACMEManager, error) {
+	if len(cfg.Hosts) == 0 {
+		return nil, errors.New("httpx: ACME hosts are required")
+	}
+	for _, c := range cfg.Challenges {
+		if c != ChallengeHTTP01 && c != ChallengeTLSALPN01 {
+			return nil, fmt.Errorf("httpx: unsupported ACME challenge %q", c)
+		}
+	}
+	if cfg.RenewBefore < 0 {
+		return nil, errors.New("httpx: ACME RenewBefore must not be negative")
+	}
+
+	if cfg.DirectoryURL == "" {
+		cfg.DirectoryURL = LetsEncryptURL
+	}
+	if cfg.Cache == nil {
+		cfg.Cache = &MemoryCache{}
+	}
+	if cfg.RenewBefore == 0 {
+		cfg.RenewBefore = 30 * 24 * time.Hour
+	}
+	if len(cfg.Challenges) == 0 {
+		cfg.Challenges = []string{ChallengeTLSALPN01, ChallengeHTTP01}
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = NewPooledClient()
+		cfg.HTTPClient.Timeout = 30 * time.Second
+	}
+
+	hosts := make([]string, len(cfg.Hosts))
+	for i, h := range cfg.Hosts {
+		hosts[i] = normalizeHost(h)
+	}
+	cfg.Hosts = hosts
+
+	return &ACMEManager{
+		cfg:        cfg,
+		logger:     slog.Default(),
+		certs:      map[string]*tls.Certificate{},
+		inflight:   map[string]*acmeCall{},
+		retryAfter: map[string]time.Time{},
+		tokens:     map[string]string{},
+		alpnCerts:  map[string]*tls.Certificate{},
+	}, nil
+}
+
+// TLSConfig returns a config serving certificates from the manager
+// with tls-alpn-01 challenge support.
+func (m *ACMEManager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: m.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1", acmeALPNProto},
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+// GetCertificate implements [tls.Config.GetCertificate].
+// Certificates are obtained on the first request and renewed in the background.
+func (m *ACMEManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := normalizeHost(hello.ServerName)
+	if host == "" {
+		return nil, errors.New("httpx: acme: missing server name")
+	}
+
+	if len(hello.SupportedProtos) == 1 && hello.SupportedProtos[0] == acmeALPNProto {
+		m.mu.Lock()
+		cert, ok := m.alpnCerts[host]
+		m.mu.Unlock()
+
+		if !ok {
+			return nil, fmt.Errorf("httpx: acme: no tls-alpn-01 challenge for %s", host)
+		}
+		return cert, nil
+	}
+
+	if !slices.Contains(m.cfg.Hosts, host) {
+		return nil, fmt.Errorf("httpx: acme: host %q is not allowed", host)
+	}
+
+	ctx := hello.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return m.certificate(ctx, host)
+}
+
+// HTTPHandler serves http-01 challenges and passes other requests to fallback.
+// If fallback is nil, 404 Not Found is returned.
+func (m *ACMEManager) HTTPHandler(fallback http.Handler) http.Handler {
+	if fallback == nil {
+		fallback = http.NotFoundHandler()
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.URL.Path, acmeChallengePrefix)
+		if !ok {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		m.mu.Lock()
+		keyAuth, ok := m.tokens[token]
+		m.mu.Unlock()
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(keyAuth))
+	})
+}
+
+// renewLoop periodically renews certificates close to expiration.
+func (m *ACMEManager) renewLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		var due []string
+		for host, cert := range m.certs {
+			if m.needsRenewal(cert) {
+				due = append(due, host)
+			}
+		}
+		m.mu.Unlock()
+
+		for _, host := range due {
+			m.renew(host)
+		}
+	}
+}
+
+func (m *ACMEManager) certificate(ctx context.Context, host string) (*tls.Certificate, error) {
+	m.mu.Lock()
+	cert, ok := m.certs[host]
+	m.mu.Unlock()
+
+	if !ok {
+		cert, ok = m.loadCached(ctx, host)
+		if ok {
+			m.mu.Lock()
+			m.certs[host] = cert
+			m.mu.Unlock()
+		}
+	}
+
+	if !ok {
+		return m.obtain(ctx, host)
+	}
+
+	if m.needsRenewal(cert) {
+		go m.renew(host)
+	}
+	return cert, nil
+}
+
+func (m *ACMEManager) needsRenewal(cert *tls.Certificate) bool {
+	return time.Until(cert.Leaf.NotAfter) < m.cfg.RenewBefore
+}
+
+func (m *ACMEManager) renew(host string) {
+	m.mu.Lock()
+	if time.Now().Before(m.retryAfter[host]) {
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+
+	if _, err := m.obtain(context.Background(), host); err != nil {
+		m.mu.Lock()
+		m.retryAfter[host] = time.Now().Add(time.Hour)
+		m.mu.Unlock()
+
+		m.logger.Error("acme certificate renewal failed", slog.String("host", host), slog.Any("error", err))
+	}
+}
+
+// obtain a new certificate, concurrent calls for the same host share the result.
+func (m *ACMEManager) obtain(ctx context.Context, host string) (*tls.Certificate, error) {
+	m.mu.Lock()
+	call, ok := m.inflight[host]
+	if !ok {
+		call = &acmeCall{done: make(chan struct{})}
+		m.inflight[host] = call
+
+		go func() {
+			// not bound to a handshake which might be canceled.
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			call.cert, call.err = m.issue(ctx, host)
+
+			m.mu.Lock()
+			delete(m.inflight, host)
+			if call.err == nil {
+				m.certs[host] = call.cert
+				delete(m.retryAfter, host)
+			}
+			m.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-call.done:
+		return call.cert, call.err
+	}
+}
+
+func (m *ACMEManager) issue(ctx context.Context, host string) (*tls.Certificate, error) {
+	client, err := m.acmeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := m.cfg.KeyType.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: host},
+		DNSNames: []string{host},
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: acme: creating CSR: %w", err)
+	}
+
+	chain, err := client.obtain(ctx, host, csr, m.cfg.Challenges, m.solve)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := newACMECert(chain, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := cert.Leaf.VerifyHostname(host); err != nil {
+		return nil, fmt.Errorf("httpx: acme: %w", err)
+	}
+
+	data, err := encodeACMECert(chain, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.cfg.Cache.Put(ctx, host, data); err != nil {
+		return nil, fmt.Errorf("httpx: acme: caching certificate: %w", err)
+	}
+	return cert, nil
+}
+
+func (m *ACMEManager) solve(ch acmeChallenge, host, keyAuth string) (func(), error) {
+	switch ch.Type {
+	case ChallengeHTTP01:
+		m.mu.Lock()
+		m.tokens[ch.Token] = keyAuth
+		m.mu.Unlock()
+
+		return func() {
+			m.mu.Lock()
+			delete(m.tokens, ch.Token)
+			m.mu.Unlock()
+		}, nil
+
+	case ChallengeTLSALPN01:
+		cert, err := newALPNChallengeCert(host, keyAuth)
+		if err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+		m.alpnCerts[host] = cert
+		m.mu.Unlock()
+
+		return func() {
+			m.mu.Lock()
+			delete(m.alpnCerts, host)
+			m.mu.Unlock()
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("httpx: acme: unsupported challenge %q", ch.Type)
+	}
+}
+
+// acmeClient returns a client with a registered account.
+func (m *ACMEManager) acmeClient(ctx context.Context) (*acmeClient, error) {
+	m.clientMu.Lock()
+	defer m.clientMu.Unlock()
+
+	if m.client != nil {
+		return m.client, nil
+	}
+
+	key, err := m.accountKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newACMEClient(ctx, m.cfg.HTTPClient, m.cfg.DirectoryURL, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.register(ctx, m.cfg.Email); err != nil {
+		return nil, err
+	}
+
+	m.client = client
+	return client, nil
+}
+
+func (m *ACMEManager) accountKey(ctx context.Context) (*ecdsa.PrivateKey, error) {
+	data, err := m.cfg.Cache.Get(ctx, acmeAccountKey)
+	switch {
+	case err == nil:
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("httpx: acme: malformed cached account key")
+		}
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("httpx: acme: parsing cached account key: %w", err)
+		}
+		return key, nil
+
+	case !errors.Is(err, ErrCacheMiss):
+		return nil, fmt.Errorf("httpx: acme: reading account key: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err := m.cfg.Cache.Put(ctx, acmeAccountKey, data); err != nil {
+		return nil, fmt.Errorf("httpx: acme: caching account key: %w", err)
+	}
+	return key, nil
+}
+
+func (m *ACMEManager) loadCached(ctx context.Context, host string) (*tls.Certificate, bool) {
+	data, err := m.cfg.Cache.Get(ctx, host)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			m.logger.Warn("acme cache read failed", slog.String("host", host), slog.Any("error", err))
+		}
+		return nil, false
+	}
+
+	var key crypto.Signer
+	var chain [][]byte
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "PRIVATE KEY":
+			k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, false
+			}
+			key, _ = k.(crypto.Signer)
+		case "CERTIFICATE":
+			chain = append(chain, block.Bytes)
+		}
+	}
+	if key == nil || len(chain) == 0 {
+		return nil, false
+	}
+
+	cert, err := newACMECert(chain, key)
+	if err != nil || time.Now().After(cert.Leaf.NotAfter) || cert.Leaf.VerifyHostname(host) != nil {
+		return nil, false
+	}
+	return cert, true
+}
+
+func newACMECert(chain [][]byte, key crypto.Signer) (*tls.Certificate, error) {
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("httpx: acme: parsing certificate: %w", err)
+	}
+	return &tls.Certificate{
+		Certificate: chain,
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+func encodeACMECert(chain [][]byte, key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	pem.Encode(&buf, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	for _, c := range chain {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: c})
+	}
+	return buf.Bytes(), nil
+}
+
+// newALPNChallengeCert returns a self-signed certificate for tls-alpn-01, see RFC 8737.
+func newALPNChallengeCert(host, keyAuth string) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(keyAuth))
+	ext, err := asn1.Marshal(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:    serial,
+		Subject:         pkix.Name{CommonName: host},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(24 * time.Hour),
+		DNSNames:        []string{host},
+		ExtraExtensions: []pkix.Extension{{Id: oidACMEIdentifier, Critical: true, Value: ext}},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: acme: creating challenge certificate: %w", err)
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
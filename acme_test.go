@@ -0,0 +1,362 @@
+package httpx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestACMEManager(t *testing.T) {
+	acme := newACMEStandIn(t)
+
+	cache := &MemoryCache{}
+	newManager := func() *ACMEManager {
+		m, err := NewACMEManager(ACMEConfig{
+			DirectoryURL: acme.srv.URL + "/dir",
+			Hosts:        []string{"example.com"},
+			Challenges:   []string{ChallengeHTTP01},
+			Cache:        cache,
+			HTTPClient:   acme.srv.Client(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	m := newManager()
+	challengeSrv := httptest.NewServer(m.HTTPHandler(nil))
+	defer challengeSrv.Close()
+	acme.challengeURL = challengeSrv.URL
+
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "Example.com."})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := x509.VerifyOptions{DNSName: "example.com", Roots: acme.ca.CertPool()}
+	if _, err := cert.Leaf.Verify(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != cert {
+		t.Error("certificate must be reused")
+	}
+
+	if _, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "evil.com"}); err == nil {
+		t.Error("certificate for not allowed host must not be issued")
+	}
+
+	// new manager with the same cache must not order a new certificate.
+	cached, err := newManager().GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cached.Leaf.Equal(cert.Leaf) {
+		t.Error("certificate must be loaded from cache")
+	}
+
+	if acme.orders != 1 {
+		t.Errorf("want 1 order, have %d", acme.orders)
+	}
+}
+
+func TestACMEManagerFinalizeError(t *testing.T) {
+	acme := newACMEStandIn(t)
+	acme.finalizeStatus = "ready"
+
+	m, err := NewACMEManager(ACMEConfig{
+		DirectoryURL: acme.srv.URL + "/dir",
+		Hosts:        []string{"example.com"},
+		Challenges:   []string{ChallengeHTTP01},
+		HTTPClient:   acme.srv.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	challengeSrv := httptest.NewServer(m.HTTPHandler(nil))
+	defer challengeSrv.Close()
+	acme.challengeURL = challengeSrv.URL
+
+	_, err = m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err == nil || !strings.Contains(err.Error(), "key is too weak") {
+		t.Errorf("want finalize error; have %v", err)
+	}
+}
+
+func TestACMEManagerTLSALPN(t *testing.T) {
+	m, err := NewACMEManager(ACMEConfig{Hosts: []string{"example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleanup, err := m.solve(acmeChallenge{Type: ChallengeTLSALPN01}, "example.com", "token.thumb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{
+		ServerName:      "example.com",
+		SupportedProtos: []string{acmeALPNProto},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256([]byte("token.thumb"))
+	var found bool
+	for _, ext := range leaf.Extensions {
+		if ext.Id.Equal(oidACMEIdentifier) {
+			var value []byte
+			if _, err := asn1.Unmarshal(ext.Value, &value); err != nil || string(value) != string(sum[:]) || !ext.Critical {
+				t.Errorf("malformed acmeIdentifier extension: %v", err)
+			}
+			found = true
+		}
+	}
+	if !found {
+		t.Error("acmeIdentifier extension is missing")
+	}
+
+	cleanup()
+	if _, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{acmeALPNProto}}); err == nil {
+		t.Error("challenge certificate must be removed")
+	}
+}
+
+// acmeStandIn is a minimal ACME server which validates http-01 challenges
+// and issues certificates signed by [DevCA].
+type acmeStandIn struct {
+	t            *testing.T
+	srv          *httptest.Server
+	ca           *DevCA
+	challengeURL string
+
+	mu     sync.Mutex
+	nonce  int
+	key    *ecdsa.PublicKey
+	thumb  string
+	host   string
+	valid  bool
+	orders int
+	chain  []byte
+
+	// order status after finalize request, "valid" if empty.
+	finalizeStatus string
+	finalized      bool
+}
+
+const acmeStandInToken = "token-123"
+
+func newACMEStandIn(t *testing.T) *acmeStandIn {
+	ca, err := NewDevCA(KeyECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &acmeStandIn{t: t, ca: ca}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+func (s *acmeStandIn) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nonce++
+	w.Header().Set("Replay-Nonce", "nonce-"+strconv.Itoa(s.nonce))
+
+	if r.URL.Path == "/dir" {
+		ReturnOKJSON(w, acmeDirectory{
+			NewNonce:   s.srv.URL + "/nonce",
+			NewAccount: s.srv.URL + "/account",
+			NewOrder:   s.srv.URL + "/order",
+		})
+		return
+	}
+	if r.URL.Path == "/nonce" {
+		return
+	}
+
+	payload, ok := s.verify(w, r)
+	if !ok {
+		return
+	}
+
+	order := func(status string) acmeOrder {
+		return acmeOrder{
+			Status:         status,
+			Authorizations: []string{s.srv.URL + "/authz/1"},
+			Finalize:       s.srv.URL + "/finalize/1",
+			Certificate:    s.srv.URL + "/cert/1",
+		}
+	}
+
+	switch r.URL.Path {
+	case "/account":
+		w.Header().Set("Location", s.srv.URL+"/account/1")
+		MarshalResponse(w, http.StatusCreated, map[string]string{"status": "valid"})
+
+	case "/order":
+		var req struct{ Identifiers []acmeIdentifier }
+		json.Unmarshal(payload, &req)
+		s.host, s.valid, s.finalized = req.Identifiers[0].Value, false, false
+		s.orders++
+
+		w.Header().Set("Location", s.srv.URL+"/order/1")
+		MarshalResponse(w, http.StatusCreated, order("pending"))
+
+	case "/authz/1":
+		status := "pending"
+		if s.valid {
+			status = "valid"
+		}
+		ReturnOKJSON(w, acmeAuthorization{
+			Status:     status,
+			Identifier: acmeIdentifier{Type: "dns", Value: s.host},
+			Challenges: []acmeChallenge{
+				{Type: ChallengeHTTP01, URL: s.srv.URL + "/chal/1", Token: acmeStandInToken, Status: status},
+			},
+		})
+
+	case "/chal/1":
+		resp, err := http.Get(s.challengeURL + acmeChallengePrefix + acmeStandInToken)
+		if err != nil {
+			s.t.Error(err)
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		s.valid = string(body) == acmeStandInToken+"."+s.thumb
+		ReturnOKJSON(w, acmeChallenge{Type: ChallengeHTTP01, Token: acmeStandInToken, Status: "processing"})
+
+	case "/finalize/1":
+		if !s.valid || s.finalized {
+			MarshalResponse(w, http.StatusForbidden, acmeError{Type: "urn:ietf:params:acme:error:orderNotReady"})
+			return
+		}
+		s.finalized = true
+
+		if s.finalizeStatus == "ready" {
+			o := order("ready")
+			o.Error = &acmeError{Type: "urn:ietf:params:acme:error:badCSR", Detail: "key is too weak"}
+			ReturnOKJSON(w, o)
+			return
+		}
+
+		var req struct{ CSR string }
+		json.Unmarshal(payload, &req)
+		der, _ := base64.RawURLEncoding.DecodeString(req.CSR)
+		csr, err := x509.ParseCertificateRequest(der)
+		if err != nil {
+			s.t.Error(err)
+			return
+		}
+
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(s.orders)),
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+		leaf, err := x509.CreateCertificate(rand.Reader, tmpl, s.ca.Cert, csr.PublicKey, s.ca.Key)
+		if err != nil {
+			s.t.Error(err)
+			return
+		}
+		s.chain = append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf}),
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.ca.Cert.Raw})...)
+
+		ReturnOKJSON(w, order("valid"))
+
+	case "/order/1":
+		switch {
+		case !s.valid:
+			ReturnOKJSON(w, order("pending"))
+		case !s.finalized:
+			ReturnOKJSON(w, order("ready"))
+		default:
+			ReturnOKJSON(w, order("valid"))
+		}
+
+	case "/cert/1":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(s.chain)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// verify JWS signature and return the payload.
+func (s *acmeStandIn) verify(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	var jws struct{ Protected, Payload, Signature string }
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		s.t.Errorf("%s: %v", r.URL.Path, err)
+		return nil, false
+	}
+
+	b64 := base64.RawURLEncoding
+	rawProtected, _ := b64.DecodeString(jws.Protected)
+	payload, _ := b64.DecodeString(jws.Payload)
+	sig, _ := b64.DecodeString(jws.Signature)
+
+	var protected struct {
+		Alg, Nonce, URL, Kid string
+		JWK                  *acmeJWKey
+	}
+	json.Unmarshal(rawProtected, &protected)
+
+	if protected.URL != s.srv.URL+r.URL.Path {
+		s.t.Errorf("url want %q; have %q", s.srv.URL+r.URL.Path, protected.URL)
+	}
+
+	if protected.JWK != nil {
+		x, _ := b64.DecodeString(protected.JWK.X)
+		y, _ := b64.DecodeString(protected.JWK.Y)
+		s.key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+
+		raw, _ := json.Marshal(protected.JWK)
+		sum := sha256.Sum256(raw)
+		s.thumb = b64.EncodeToString(sum[:])
+	} else if protected.Kid != s.srv.URL+"/account/1" {
+		s.t.Errorf("unexpected kid %q", protected.Kid)
+	}
+
+	hash := sha256.Sum256([]byte(jws.Protected + "." + jws.Payload))
+	r1, r2 := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if s.key == nil || !ecdsa.Verify(s.key, hash[:], r1, r2) {
+		MarshalResponse(w, http.StatusUnauthorized, acmeError{Type: "urn:ietf:params:acme:error:malformed"})
+		return nil, false
+	}
+	return payload, true
+}
@@ -0,0 +1,424 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// acmeClient implements the subset of RFC 8555 required to obtain certificates.
+type acmeClient struct {
+	client *http.Client
+	key    *ecdsa.PrivateKey
+	dir    acmeDirectory
+	kid    string // account URL.
+
+	mu    sync.Mutex
+	nonce string
+}
+
+type acmeDirectory struct {
+	NewNonce   string `json:"newNonce"`
+	NewAccount string `json:"newAccount"`
+	NewOrder   string `json:"newOrder"`
+}
+
+type acmeOrder struct {
+	Status         string     `json:"status"`
+	Authorizations []string   `json:"authorizations"`
+	Finalize       string     `json:"finalize"`
+	Certificate    string     `json:"certificate"`
+	Error          *acmeError `json:"error"`
+}
+
+type acmeIdentifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type acmeAuthorization struct {
+	Status     string          `json:"status"`
+	Identifier acmeIdentifier  `json:"identifier"`
+	Challenges []acmeChallenge `json:"challenges"`
+}
+
+type acmeChallenge struct {
+	Type   string     `json:"type"`
+	URL    string     `json:"url"`
+	Token  string     `json:"token"`
+	Status string     `json:"status"`
+	Error  *acmeError `json:"error"`
+}
+
+// acmeError is a problem document returned by ACME server.
+type acmeError struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+func (e *acmeError) Error() string {
+	return fmt.Sprintf("httpx: acme: %s: %s", e.Type, e.Detail)
+}
+
+const acmeErrBadNonce = "urn:ietf:params:acme:error:badNonce"
+
+// acmeSolver prepares a challenge response and returns a cleanup function.
+type acmeSolver func(ch acmeChallenge, host, keyAuth string) (func(), error)
+
+func newACMEClient(ctx context.Context, client *http.Client, directoryURL string, key *ecdsa.PrivateKey) (*acmeClient, error) {
+	c := &acmeClient{client: client, key: key}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, directoryURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: acme: fetching directory: %w", err)
+	}
+	defer DiscardResponseBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("httpx: acme: fetching directory: unexpected status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&c.dir); err != nil {
+		return nil, fmt.Errorf("httpx: acme: decoding directory: %w", err)
+	}
+	return c, nil
+}
+
+// register a new account or find an existing one for the key.
+func (c *acmeClient) register(ctx context.Context, email string) error {
+	payload := map[string]any{"termsOfServiceAgreed": true}
+	if email != "" {
+		payload["contact"] = []string{"mailto:" + email}
+	}
+
+	resp, _, err := c.post(ctx, c.dir.NewAccount, payload)
+	if err != nil {
+		return err
+	}
+	c.kid = resp.Header.Get("Location")
+	if c.kid == "" {
+		return errors.New("httpx: acme: account URL is missing")
+	}
+	return nil
+}
+
+// obtain a certificate chain in DER for a host.
+func (c *acmeClient) obtain(ctx context.Context, host string, csr []byte, preferred []string, solve acmeSolver) ([][]byte, error) {
+	var order acmeOrder
+	resp, err := c.postJSON(ctx, c.dir.NewOrder, map[string]any{
+		"identifiers": []acmeIdentifier{{Type: "dns", Value: host}},
+	}, &order)
+	if err != nil {
+		return nil, err
+	}
+	orderURL := resp.Header.Get("Location")
+
+	for _, authzURL := range order.Authorizations {
+		if err := c.authorize(ctx, authzURL, preferred, solve); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp, err = c.postJSON(ctx, orderURL, nil, &order); err != nil {
+		return nil, err
+	}
+
+	// order is finalized once it's ready, then the certificate is issued.
+	finalized := false
+	for order.Status != "valid" {
+		switch order.Status {
+		case "invalid":
+			return nil, fmt.Errorf("httpx: acme: order is invalid: %w", order.Error)
+		case "ready":
+			if finalized {
+				// finalization failed, order.Error has details.
+				if order.Error != nil {
+					return nil, fmt.Errorf("httpx: acme: order is not finalized: %w", order.Error)
+				}
+				return nil, errors.New("httpx: acme: order is not finalized")
+			}
+			finalized = true
+
+			resp, err = c.postJSON(ctx, order.Finalize, map[string]string{
+				"csr": base64.RawURLEncoding.EncodeToString(csr),
+			}, &order)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		case "pending", "processing":
+		default:
+			return nil, fmt.Errorf("httpx: acme: unexpected order status %q", order.Status)
+		}
+
+		if err := acmeWait(ctx, resp); err != nil {
+			return nil, err
+		}
+		if resp, err = c.postJSON(ctx, orderURL, nil, &order); err != nil {
+			return nil, err
+		}
+	}
+
+	_, raw, err := c.post(ctx, order.Certificate, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain [][]byte
+	for block, rest := pem.Decode(raw); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			chain = append(chain, block.Bytes)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("httpx: acme: no certificates in response")
+	}
+	return chain, nil
+}
+
+func (c *acmeClient) authorize(ctx context.Context, authzURL string, preferred []string, solve acmeSolver) error {
+	var authz acmeAuthorization
+	resp, err := c.postJSON(ctx, authzURL, nil, &authz)
+	if err != nil {
+		return err
+	}
+	if authz.Status == "valid" {
+		return nil
+	}
+
+	ch, ok := pickChallenge(authz.Challenges, preferred)
+	if !ok {
+		return fmt.Errorf("httpx: acme: no supported challenges for %s", authz.Identifier.Value)
+	}
+
+	keyAuth := ch.Token + "." + c.thumbprint()
+	cleanup, err := solve(ch, authz.Identifier.Value, keyAuth)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if _, err := c.postJSON(ctx, ch.URL, struct{}{}, &ch); err != nil {
+		return err
+	}
+
+	for {
+		if resp, err = c.postJSON(ctx, authzURL, nil, &authz); err != nil {
+			return err
+		}
+
+		switch authz.Status {
+		case "valid":
+			return nil
+		case "pending", "processing":
+		default:
+			for _, ch := range authz.Challenges {
+				if ch.Error != nil {
+					return fmt.Errorf("httpx: acme: authorization for %s failed: %w", authz.Identifier.Value, ch.Error)
+				}
+			}
+			return fmt.Errorf("httpx: acme: authorization for %s is %s", authz.Identifier.Value, authz.Status)
+		}
+
+		if err := acmeWait(ctx, resp); err != nil {
+			return err
+		}
+	}
+}
+
+func pickChallenge(challenges []acmeChallenge, preferred []string) (acmeChallenge, bool) {
+	for _, typ := range preferred {
+		for _, ch := range challenges {
+			if ch.Type == typ {
+				return ch, true
+			}
+		}
+	}
+	return acmeChallenge{}, false
+}
+
+// acmeWait sleeps for Retry-After or 1 second.
+func acmeWait(ctx context.Context, resp *http.Response) error {
+	d := time.Second
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+		d = time.Duration(s) * time.Second
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *acmeClient) postJSON(ctx context.Context, url string, payload, out any) (*http.Response, error) {
+	resp, raw, err := c.post(ctx, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return nil, fmt.Errorf("httpx: acme: decoding %s: %w", url, err)
+	}
+	return resp, nil
+}
+
+// post a JWS signed request, nil payload means POST-as-GET.
+func (c *acmeClient) post(ctx context.Context, url string, payload any) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		nonce, err := c.fetchNonce(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		body, err := c.sign(url, nonce, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Content-Type", "application/jose+json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("httpx: acme: %w", err)
+		}
+		raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("httpx: acme: %w", err)
+		}
+
+		c.mu.Lock()
+		c.nonce = resp.Header.Get("Replay-Nonce")
+		c.mu.Unlock()
+
+		if resp.StatusCode < 400 {
+			return resp, raw, nil
+		}
+
+		acmeErr := &acmeError{Status: resp.StatusCode}
+		if json.Unmarshal(raw, acmeErr) != nil || acmeErr.Type == "" {
+			acmeErr.Type, acmeErr.Detail = "unknown", string(raw)
+		}
+		if acmeErr.Type == acmeErrBadNonce && attempt < 3 {
+			continue
+		}
+		return nil, nil, acmeErr
+	}
+}
+
+func (c *acmeClient) fetchNonce(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	nonce := c.nonce
+	c.nonce = ""
+	c.mu.Unlock()
+
+	if nonce != "" {
+		return nonce, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.dir.NewNonce, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("httpx: acme: fetching nonce: %w", err)
+	}
+	DiscardResponseBody(resp)
+
+	nonce = resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return "", errors.New("httpx: acme: nonce is missing")
+	}
+	return nonce, nil
+}
+
+func (c *acmeClient) sign(url, nonce string, payload any) ([]byte, error) {
+	protected := map[string]any{"alg": "ES256", "nonce": nonce, "url": url}
+	if c.kid == "" {
+		protected["jwk"] = acmeJWK(&c.key.PublicKey)
+	} else {
+		protected["kid"] = c.kid
+	}
+
+	rawProtected, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawPayload []byte
+	if payload != nil {
+		if rawPayload, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	b64 := base64.RawURLEncoding
+	signingInput := b64.EncodeToString(rawProtected) + "." + b64.EncodeToString(rawPayload)
+	hash := sha256.Sum256([]byte(signingInput))
+
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return json.Marshal(map[string]string{
+		"protected": b64.EncodeToString(rawProtected),
+		"payload":   b64.EncodeToString(rawPayload),
+		"signature": b64.EncodeToString(sig),
+	})
+}
+
+// acmeJWKey is a P-256 JSON Web Key, fields are in the order required for thumbprint.
+type acmeJWKey struct {
+	Crv string `json:"crv"`
+	Kty string `json:"kty"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func acmeJWK(pub *ecdsa.PublicKey) acmeJWKey {
+	// uncompressed point: 0x04 || X || Y
+	point, _ := pub.ECDH()
+	raw := point.Bytes()
+	return acmeJWKey{
+		Crv: "P-256",
+		Kty: "EC",
+		X:   base64.RawURLEncoding.EncodeToString(raw[1:33]),
+		Y:   base64.RawURLEncoding.EncodeToString(raw[33:]),
+	}
+}
+
+// thumbprint of the account key, see RFC 7638.
+func (c *acmeClient) thumbprint() string {
+	raw, _ := json.Marshal(acmeJWK(&c.key.PublicKey))
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,266 @@
+package httpx
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"net/netip"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// AdminConfig configures [NewAdminHandler].
+type AdminConfig struct {
+	// Prefix of all the endpoints. Default is "/debug".
+	Prefix string
+
+	// Auth checks access to the endpoints.
+	// If nil only requests from a loopback address are allowed.
+	Auth func(r *http.Request) bool
+
+	// ServerConfig to expose at {Prefix}/config. Optional.
+	// Only values of addresses, protocols, timeouts and limits are exposed,
+	// other fields like TLSConfig and ACME are reported as set or not.
+	ServerConfig *ServerConfig
+
+	// Router to list registered routes at {Prefix}/routes. Optional.
+	Router *Router
+}
+
+// NewAdminHandler returns a handler with debug endpoints:
+//
+//   - {Prefix}/pprof/ - profiles from [net/http/pprof].
+//   - {Prefix}/vars - variables from [expvar].
+//   - {Prefix}/runtime - goroutines, memory and GC stats.
+//   - {Prefix}/buildinfo - build info from [debug.ReadBuildInfo].
+//   - {Prefix}/config - effective [ServerConfig].
+//   - {Prefix}/routes - routes registered in [Router].
+//
+// The handler should be mounted on a separate [Server] which is not exposed publicly.
+func NewAdminHandler(cfg AdminConfig) http.Handler {
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = "/debug"
+	}
+
+	auth := cfg.Auth
+	if auth == nil {
+		auth = isLoopbackRequest
+	}
+
+	r := NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !auth(req) {
+				ErrorResponse(w, http.StatusForbidden, errors.New("access denied"))
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+
+	r.HandleFunc("GET "+prefix+"/pprof/", pprof.Index)
+	r.HandleFunc("GET "+prefix+"/pprof/cmdline", pprof.Cmdline)
+	r.HandleFunc("GET "+prefix+"/pprof/profile", pprof.Profile)
+	r.HandleFunc("GET "+prefix+"/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("POST "+prefix+"/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("GET "+prefix+"/pprof/trace", pprof.Trace)
+	r.HandleFunc("GET "+prefix+"/pprof/{profile}", func(w http.ResponseWriter, req *http.Request) {
+		pprof.Handler(req.PathValue("profile")).ServeHTTP(w, req)
+	})
+
+	r.Handle("GET "+prefix+"/vars", expvar.Handler())
+
+	r.HandleFunc("GET "+prefix+"/runtime", func(w http.ResponseWriter, req *http.Request) {
+		ReturnOKJSON(w, readRuntimeStats())
+	})
+
+	r.HandleFunc("GET "+prefix+"/buildinfo", func(w http.ResponseWriter, req *http.Request) {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			ErrorResponse(w, http.StatusNotFound, errors.New("build info is not available"))
+			return
+		}
+		ReturnOKJSON(w, info)
+	})
+
+	if cfg.ServerConfig != nil {
+		r.HandleFunc("GET "+prefix+"/config", func(w http.ResponseWriter, req *http.Request) {
+			ReturnOKJSON(w, serverConfigView(cfg.ServerConfig))
+		})
+	}
+
+	if cfg.Router != nil {
+		r.HandleFunc("GET "+prefix+"/routes", func(w http.ResponseWriter, req *http.Request) {
+			ReturnOKJSON(w, cfg.Router.Routes())
+		})
+	}
+	return r
+}
+
+func isLoopbackRequest(r *http.Request) bool {
+	ap, err := netip.ParseAddrPort(r.RemoteAddr)
+	return err == nil && ap.Addr().IsLoopback()
+}
+
+type runtimeStats struct {
+	GoVersion  string `json:"go_version"`
+	GOOS       string `json:"goos"`
+	GOARCH     string `json:"goarch"`
+	NumCPU     int    `json:"num_cpu"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	Goroutines int    `json:"goroutines"`
+
+	Memory struct {
+		Alloc        uint64 `json:"alloc"`
+		TotalAlloc   uint64 `json:"total_alloc"`
+		Sys          uint64 `json:"sys"`
+		HeapAlloc    uint64 `json:"heap_alloc"`
+		HeapInuse    uint64 `json:"heap_inuse"`
+		HeapObjects  uint64 `json:"heap_objects"`
+		StackInuse   uint64 `json:"stack_inuse"`
+		Mallocs      uint64 `json:"mallocs"`
+		Frees        uint64 `json:"frees"`
+		NextGCTarget uint64 `json:"next_gc"`
+	} `json:"memory"`
+
+	GC struct {
+		NumGC         uint32    `json:"num_gc"`
+		NumForcedGC   uint32    `json:"num_forced_gc"`
+		PauseTotal    string    `json:"pause_total"`
+		LastGC        time.Time `json:"last_gc"`
+		GCCPUFraction float64   `json:"gc_cpu_fraction"`
+	} `json:"gc"`
+}
+
+func readRuntimeStats() runtimeStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	stats := runtimeStats{
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Goroutines: runtime.NumGoroutine(),
+	}
+
+	stats.Memory.Alloc = ms.Alloc
+	stats.Memory.TotalAlloc = ms.TotalAlloc
+	stats.Memory.Sys = ms.Sys
+	stats.Memory.HeapAlloc = ms.HeapAlloc
+	stats.Memory.HeapInuse = ms.HeapInuse
+	stats.Memory.HeapObjects = ms.HeapObjects
+	stats.Memory.StackInuse = ms.StackInuse
+	stats.Memory.Mallocs = ms.Mallocs
+	stats.Memory.Frees = ms.Frees
+	stats.Memory.NextGCTarget = ms.NextGC
+
+	stats.GC.NumGC = ms.NumGC
+	stats.GC.NumForcedGC = ms.NumForcedGC
+	stats.GC.PauseTotal = time.Duration(ms.PauseTotalNs).String()
+	if ms.LastGC != 0 {
+		stats.GC.LastGC = time.Unix(0, int64(ms.LastGC))
+	}
+	stats.GC.GCCPUFraction = ms.GCCPUFraction
+	return stats
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// configViewFields of [ServerConfig] are exposed with values.
+// It's an allowlist, so secrets added to the config later are not exposed.
+var configViewFields = map[string]bool{
+	"Addr":              true,
+	"Protocols":         true,
+	"NoHTTP2":           true,
+	"HTTP2":             true,
+	"RedirectAddr":      true,
+	"RedirectStatus":    true,
+	"HSTS":              true,
+	"HeartbeatPath":     true,
+	"ReadTimeout":       true,
+	"ReadHeaderTimeout": true,
+	"WriteTimeout":      true,
+	"IdleTimeout":       true,
+	"MaxHeaderBytes":    true,
+	"ShutdownTimeout":   true,
+	"ProxyProtocol":     true,
+	"MaxConns":          true,
+	"MaxConnsPerIP":     true,
+	"ConnLimitMode":     true,
+	"ConnQueueTimeout":  true,
+	"LogConnState":      true,
+}
+
+// serverConfigView returns values of [configViewFields], other fields are reported as set or not.
+func serverConfigView(cfg *ServerConfig) map[string]any {
+	v := reflect.ValueOf(cfg).Elem()
+	fields := map[string]any{}
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		switch {
+		case !f.IsExported():
+		case configViewFields[f.Name]:
+			fields[f.Name] = configView(v.Field(i))
+		default:
+			fields[f.Name] = !v.Field(i).IsZero()
+		}
+	}
+	return fields
+}
+
+// configView returns a JSON friendly view of a config.
+// Functions and interfaces are reported only as set or not.
+func configView(v reflect.Value) any {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Func, reflect.Interface, reflect.Chan:
+		return !v.IsNil()
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+		return configView(v.Elem())
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		items := make([]any, v.Len())
+		for i := range v.Len() {
+			items[i] = configView(v.Index(i))
+		}
+		return items
+
+	case reflect.Struct:
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+		fields := map[string]any{}
+		for i := range v.NumField() {
+			if f := v.Type().Field(i); f.IsExported() {
+				fields[f.Name] = configView(v.Field(i))
+			}
+		}
+		if len(fields) == 0 {
+			return true
+		}
+		return fields
+
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,89 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	router := NewRouter()
+	router.HandleFunc("GET /users/{id}", NoopHandler)
+
+	h := NewAdminHandler(AdminConfig{
+		ServerConfig: &ServerConfig{
+			Addr: ":8080",
+			ACME: &ACMEConfig{
+				Email:        "admin@example.com",
+				DirectoryURL: "https://acme.internal/directory",
+				Cache:        DirCache("/var/lib/secret-certs"),
+			},
+		},
+		Router: router,
+	})
+
+	tests := []struct {
+		Path       string
+		RemoteAddr string
+		WantStatus int
+		WantBody   string
+	}{
+		{
+			Path:       "/debug/routes",
+			RemoteAddr: "127.0.0.1:1234",
+			WantStatus: http.StatusOK,
+			WantBody:   `"pattern":"GET /users/{id}"`,
+		},
+		{
+			Path:       "/debug/config",
+			RemoteAddr: "[::1]:1234",
+			WantStatus: http.StatusOK,
+			WantBody:   `"Addr":":8080"`,
+		},
+		{
+			Path:       "/debug/config",
+			RemoteAddr: "127.0.0.1:1234",
+			WantStatus: http.StatusOK,
+			WantBody:   `"ACME":true`,
+		},
+		{
+			Path:       "/debug/runtime",
+			RemoteAddr: "127.0.0.1:1234",
+			WantStatus: http.StatusOK,
+			WantBody:   `"goroutines"`,
+		},
+		{
+			Path:       "/debug/routes",
+			RemoteAddr: "10.0.0.1:1234",
+			WantStatus: http.StatusForbidden,
+			WantBody:   "access denied",
+		},
+	}
+
+	for _, test := range tests {
+		rq := httptest.NewRequest("GET", test.Path, nil)
+		rq.RemoteAddr = test.RemoteAddr
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, rq)
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s: status want %d; have %d", test.Path, test.WantStatus, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, test.WantBody) {
+			t.Errorf("%s: body want %q; have %q", test.Path, test.WantBody, body)
+		}
+	}
+
+	rq := httptest.NewRequest("GET", "/debug/config", nil)
+	rq.RemoteAddr = "127.0.0.1:1234"
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, rq)
+
+	for _, secret := range []string{"admin@example.com", "acme.internal", "secret-certs"} {
+		if strings.Contains(rr.Body.String(), secret) {
+			t.Errorf("config must not expose %q: %s", secret, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldError is an error of a single request field.
+type FieldError struct {
+	// Field name or path like "address.city".
+	Field string `json:"field"`
+	// In is a location of the value: "path", "query", "header", "cookie" or "form".
+	// Empty for the body fields.
+	In      string `json:"in,omitempty"`
+	Message string `json:"msg"`
+}
+
+// bindTags are struct tags with names of request values.
+var bindTags = []string{"path", "query", "header", "cookie", "form"}
+
+// Bind request values to fields of a struct pointed by v.
+// Values are taken by struct tags:
+//
+//	type ListUsers struct {
+//		Tenant string        `path:"tenant"`
+//		Limit  int           `query:"limit" default:"20"`
+//		IDs    []int64       `query:"id"`
+//		Since  time.Time     `query:"since"`
+//		Wait   time.Duration `header:"X-Wait"`
+//		Session string       `cookie:"sid"`
+//		Name   string        `form:"name"`
+//	}
+//
+// Supported types are strings, booleans, numbers, [time.Duration],
+// [encoding.TextUnmarshaler] (like [time.Time] in RFC 3339), pointers and slices of them.
+// Slices are filled from repeated values, default value of a slice is comma-separated.
+// Untagged embedded structs are bound too.
+//
+// All the invalid values are reported in [Error.Fields] with 400 Bad Request code.
+func Bind(r *http.Request, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || !isStruct(rv.Type()) {
+		return fmt.Errorf("httpx: Bind requires a pointer to a struct, got %T", v)
+	}
+
+	b := &binder{r: r}
+	b.bindStruct(rv.Elem())
+
+	if len(b.errs) > 0 {
+		return &Error{
+			Code:    http.StatusBadRequest,
+			Message: "invalid request parameters",
+			Fields:  b.errs,
+		}
+	}
+	return nil
+}
+
+type binder struct {
+	r        *http.Request
+	errs     []FieldError
+	formDone bool
+}
+
+func (b *binder) bindStruct(v reflect.Value) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+
+		in, name := fieldBinding(f)
+		if in == "" {
+			if f.Anonymous && isStruct(f.Type) && f.Tag.Get("json") == "" {
+				if f.Type.Kind() == reflect.Pointer && !f.IsExported() {
+					continue // cannot allocate unexported embedded pointer.
+				}
+				b.bindStruct(v.Field(i))
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		values := b.values(in, name)
+		if len(values) == 0 {
+			def, ok := f.Tag.Lookup("default")
+			if !ok {
+				continue
+			}
+			values = []string{def}
+			if indirect(f.Type).Kind() == reflect.Slice && !isTextUnmarshaler(indirect(f.Type)) {
+				values = splitList(def)
+			}
+		}
+
+		if err := setBindValue(v.Field(i), values); err != nil {
+			b.errs = append(b.errs, FieldError{Field: name, In: in, Message: bindErrorMessage(err)})
+		}
+	}
+}
+
+func (b *binder) values(in, name string) []string {
+	switch in {
+	case "path":
+		if v := b.r.PathValue(name); v != "" {
+			return []string{v}
+		}
+	case "query":
+		return b.r.URL.Query()[name]
+	case "header":
+		return b.r.Header.Values(name)
+	case "cookie":
+		if c, err := b.r.Cookie(name); err == nil {
+			return []string{c.Value}
+		}
+	case "form":
+		if !b.formDone {
+			b.formDone = true
+			if err := b.parseForm(); err != nil {
+				b.errs = append(b.errs, FieldError{Field: name, In: in, Message: err.Error()})
+			}
+		}
+		return b.r.PostForm[name]
+	}
+	return nil
+}
+
+func (b *binder) parseForm() error {
+	if strings.HasPrefix(b.r.Header.Get("Content-Type"), "multipart/form-data") {
+		err := b.r.ParseMultipartForm(32 << 20)
+		if errors.Is(err, http.ErrNotMultipart) {
+			return nil
+		}
+		return err
+	}
+	return b.r.ParseForm()
+}
+
+// fieldBinding returns the first bind tag of a field.
+func fieldBinding(f reflect.StructField) (in, name string) {
+	for _, tag := range bindTags {
+		if name, ok := f.Tag.Lookup(tag); ok {
+			return tag, name
+		}
+	}
+	return "", ""
+}
+
+func setBindValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setBindValue(elem.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice && !isTextUnmarshaler(v.Type()) {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setBindValue(s.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return setStringValue(v, values[0])
+}
+
+func bindErrorMessage(err error) string {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return fmt.Sprintf("invalid value %q: %v", numErr.Num, numErr.Err)
+	}
+	return err.Error()
+}
+
+// requestParams returns params from tagged fields of a struct
+// and whether it has fields decoded from the body.
+func requestParams(t reflect.Type) (params []Param, hasBody bool) {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return nil, true
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		in, name := fieldBinding(f)
+		switch {
+		case in == "" && f.Anonymous && isStruct(f.Type) && f.Tag.Get("json") == "":
+			embedded, body := requestParams(f.Type)
+			params = append(params, embedded...)
+			hasBody = hasBody || body
+		case !f.IsExported():
+		case in == "form":
+			hasBody = true
+		case in != "":
+			params = append(params, Param{Name: name, In: in, Type: f.Type, Required: in == "path"})
+		case f.Tag.Get("json") != "-":
+			hasBody = true
+		}
+	}
+	return params, hasBody
+}
+
+// hasFormFields reports whether a struct has fields with form tag.
+func hasFormFields(t reflect.Type) bool {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		in, _ := fieldBinding(f)
+		switch {
+		case in == "form" && f.IsExported():
+			return true
+		case in == "" && f.Anonymous && isStruct(f.Type) && f.Tag.Get("json") == "" && hasFormFields(f.Type):
+			return true
+		}
+	}
+	return false
+}
+
+// isFormRequest reports whether the request body is url-encoded or multipart form.
+func isFormRequest(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func isStruct(t reflect.Type) bool {
+	return indirect(t).Kind() == reflect.Struct
+}
+
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
@@ -0,0 +1,94 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBind(t *testing.T) {
+	type Paging struct {
+		Limit  int `query:"limit" default:"20"`
+		Offset int `query:"offset"`
+	}
+	type Request struct {
+		Paging
+		Tenant  string        `path:"tenant"`
+		IDs     []int64       `query:"id"`
+		Tags    []string      `query:"tag" default:"a,b"`
+		Since   time.Time     `query:"since"`
+		Verbose *bool         `query:"verbose"`
+		Wait    time.Duration `header:"X-Wait"`
+		IP      netip.Addr    `header:"X-Ip"`
+		Session string        `cookie:"sid"`
+		Name    string        `form:"name"`
+		Ignored string
+	}
+
+	rq := httptest.NewRequest("POST", "/tenants/acme?id=1&id=2&since=2024-01-02T03:04:05Z&verbose=true", strings.NewReader("name=Ann"))
+	rq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rq.Header.Set("X-Wait", "1.5s")
+	rq.Header.Set("X-Ip", "10.0.0.1")
+	rq.AddCookie(&http.Cookie{Name: "sid", Value: "s3cr3t"})
+	rq.SetPathValue("tenant", "acme")
+
+	var have Request
+	if err := Bind(rq, &have); err != nil {
+		t.Fatal(err)
+	}
+
+	verbose := true
+	want := Request{
+		Paging:  Paging{Limit: 20},
+		Tenant:  "acme",
+		IDs:     []int64{1, 2},
+		Tags:    []string{"a", "b"},
+		Since:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Verbose: &verbose,
+		Wait:    1500 * time.Millisecond,
+		IP:      netip.MustParseAddr("10.0.0.1"),
+		Session: "s3cr3t",
+		Name:    "Ann",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("want %+v\nhave %+v", want, have)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	var req struct {
+		Limit int           `query:"limit"`
+		IDs   []uint        `query:"id"`
+		Wait  time.Duration `header:"X-Wait"`
+		Valid string        `query:"valid"`
+	}
+
+	rq := httptest.NewRequest("GET", "/?limit=ten&id=1&id=-2&valid=ok", nil)
+	rq.Header.Set("X-Wait", "forever")
+
+	err := Bind(rq, &req)
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("want *Error; have %#v", err)
+	}
+
+	want := []FieldError{
+		{Field: "limit", In: "query", Message: `invalid value "ten": invalid syntax`},
+		{Field: "id", In: "query", Message: `invalid value "-2": invalid syntax`},
+		{Field: "X-Wait", In: "header", Message: `time: invalid duration "forever"`},
+	}
+	if e.Code != http.StatusBadRequest || !reflect.DeepEqual(e.Fields, want) {
+		t.Errorf("want %+v\nhave %+v", want, e)
+	}
+	if req.Valid != "ok" {
+		t.Errorf("valid fields must be bound, have %q", req.Valid)
+	}
+
+	if err := Bind(rq, req); err == nil {
+		t.Error("non-pointer must fail")
+	}
+}
@@ -25,9 +25,10 @@ func NewClient() *http.Client {
 }
 
 // NewPooledClient returns [http.Client] which will be used for the same host(s).
-func NewPooledClient() *http.Client {
+// See [NewPooledTransport] for options.
+func NewPooledClient(opts ...TransportOption) *http.Client {
 	return &http.Client{
-		Transport: NewPooledTransport(),
+		Transport: NewPooledTransport(opts...),
 		Timeout:   5 * time.Second,
 	}
 }
@@ -40,8 +41,32 @@ func NewTransport() *http.Transport {
 	return transport
 }
 
+// TransportOption configures [http.Transport] created by [NewPooledTransport].
+type TransportOption func(*http.Transport)
+
+// WithProtocols sets protocols of the transport.
+// To speak unencrypted HTTP/2 with prior knowledge (h2c) for http:// URLs:
+//
+//	var protocols http.Protocols
+//	protocols.SetUnencryptedHTTP2(true)
+//	client := httpx.NewPooledClient(httpx.WithProtocols(protocols))
+//
+// Server must accept such connections, see [ServerConfig.Protocols].
+func WithProtocols(protocols http.Protocols) TransportOption {
+	return func(t *http.Transport) {
+		t.Protocols = &protocols
+	}
+}
+
+// WithHTTP2 sets HTTP/2 settings of the transport like max concurrent streams and frame sizes.
+func WithHTTP2(cfg http.HTTP2Config) TransportOption {
+	return func(t *http.Transport) {
+		t.HTTP2 = &cfg
+	}
+}
+
 // NewPooledTransport returns [http.Transport] which will be used for the same host(s).
-func NewPooledTransport() *http.Transport {
+func NewPooledTransport(opts ...TransportOption) *http.Transport {
 	transport := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
@@ -56,5 +81,8 @@ func NewPooledTransport() *http.Transport {
 		ExpectContinueTimeout: 1 * time.Second,
 		ForceAttemptHTTP2:     true,
 	}
+	for _, opt := range opts {
+		opt(transport)
+	}
 	return transport
 }
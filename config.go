@@ -0,0 +1,367 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LoadEnv populates the config from environment variables with a given prefix.
+// Variable names are field names in upper snake case, nested fields are joined with '_'.
+// Slices are comma separated, protocols are any of http1, http2 and h2c:
+//
+//	APP_ADDR=:8080
+//	APP_READ_TIMEOUT=10s
+//	APP_PROTOCOLS=http1,h2c
+//	APP_PROXY_PROTOCOL_TRUSTED_CIDRS=10.0.0.0/8,192.168.0.0/16
+//	APP_HTTP2_MAX_CONCURRENT_STREAMS=100
+//
+// Handlers, TLSConfig and other non-scalar fields are not loaded.
+// Call [ServerConfig.Validate] (or [NewServer]) after loading.
+func (c *ServerConfig) LoadEnv(prefix string) error {
+	prefix = strings.TrimSuffix(prefix, "_")
+
+	lookup := func(path []string) (any, bool) {
+		name := strings.ToUpper(strings.Join(path, "_"))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		return os.LookupEnv(name)
+	}
+
+	_, err := loadConfig(reflect.ValueOf(c).Elem(), nil, lookup)
+	return err
+}
+
+// LoadFile populates the config from a JSON file.
+// Keys are field names in snake case, durations are strings:
+//
+//	{
+//	  "addr": ":8080",
+//	  "read_timeout": "10s",
+//	  "protocols": ["http1", "h2c"],
+//	  "proxy_protocol": {"trusted_cidrs": ["10.0.0.0/8"]}
+//	}
+//
+// Unknown keys are reported as errors.
+// Call [ServerConfig.Validate] (or [NewServer]) after loading.
+func (c *ServerConfig) LoadFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("httpx: reading config: %w", err)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+
+	var values map[string]any
+	if err := d.Decode(&values); err != nil {
+		return fmt.Errorf("httpx: decoding config %s: %w", path, err)
+	}
+
+	used := map[string]bool{}
+	lookup := func(path []string) (any, bool) {
+		v, ok := lookupConfigValue(values, path)
+		if ok {
+			used[strings.Join(path, ".")] = true
+		}
+		return v, ok
+	}
+
+	_, err = loadConfig(reflect.ValueOf(c).Elem(), nil, lookup)
+
+	var errs []error
+	for _, key := range configKeys(values, "") {
+		if !used[key] {
+			errs = append(errs, fmt.Errorf("httpx: unknown config key %q", key))
+		}
+	}
+	return errors.Join(err, errors.Join(errs...))
+}
+
+type configLookup func(path []string) (any, bool)
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	protocolsType       = reflect.TypeFor[*http.Protocols]()
+	http2ConfigType     = reflect.TypeFor[*http.HTTP2Config]()
+	configPkgPath       = reflect.TypeFor[ServerConfig]().PkgPath()
+)
+
+// loadConfig sets struct fields found by lookup and reports whether any was set.
+func loadConfig(v reflect.Value, path []string, lookup configLookup) (bool, error) {
+	var set bool
+	var errs []error
+
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		fpath := append(slices.Clip(path), configName(f.Name))
+
+		switch {
+		case f.Type == protocolsType:
+			val, ok := lookup(fpath)
+			if !ok {
+				continue
+			}
+			p, err := parseProtocols(val)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("httpx: config %s: %w", strings.Join(fpath, "."), err))
+				continue
+			}
+			fv.Set(reflect.ValueOf(p))
+			set = true
+
+		case isConfigLeaf(f.Type):
+			val, ok := lookup(fpath)
+			if !ok {
+				continue
+			}
+			if err := setConfigValue(fv, val); err != nil {
+				errs = append(errs, fmt.Errorf("httpx: config %s: %w", strings.Join(fpath, "."), err))
+				continue
+			}
+			set = true
+
+		case isConfigSection(f.Type):
+			section := reflect.New(f.Type.Elem())
+			if !fv.IsNil() {
+				section.Elem().Set(fv.Elem())
+			}
+			ok, err := loadConfig(section.Elem(), fpath, lookup)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if ok {
+				fv.Set(section)
+				set = true
+			}
+		}
+	}
+	return set, errors.Join(errs...)
+}
+
+// isConfigSection reports whether a nested config struct can be loaded.
+func isConfigSection(t reflect.Type) bool {
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	return t.Elem().PkgPath() == configPkgPath || t == http2ConfigType
+}
+
+func isConfigLeaf(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return isConfigLeaf(t.Elem())
+	default:
+		return false
+	}
+}
+
+func setConfigValue(v reflect.Value, val any) error {
+	switch val := val.(type) {
+	case string:
+		if v.Kind() == reflect.Slice && !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+			return setConfigValue(v, splitList(val))
+		}
+		return setStringValue(v, val)
+
+	case json.Number:
+		return setStringValue(v, val.String())
+
+	case bool:
+		if v.Kind() != reflect.Bool {
+			return fmt.Errorf("unexpected bool for %s", v.Type())
+		}
+		v.SetBool(val)
+		return nil
+
+	case []string:
+		return setConfigValue(v, toAnySlice(val))
+
+	case []any:
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("unexpected list for %s", v.Type())
+		}
+		s := reflect.MakeSlice(v.Type(), len(val), len(val))
+		for i, item := range val {
+			if err := setConfigValue(s.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+
+	default:
+		return fmt.Errorf("unexpected value %v for %s", val, v.Type())
+	}
+}
+
+func setStringValue(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func parseProtocols(val any) (*http.Protocols, error) {
+	var names []any
+	switch val := val.(type) {
+	case string:
+		names = toAnySlice(splitList(val))
+	case []any:
+		names = val
+	default:
+		return nil, fmt.Errorf("unexpected value %v for protocols", val)
+	}
+
+	var p http.Protocols
+	for _, name := range names {
+		switch name {
+		case "http1":
+			p.SetHTTP1(true)
+		case "http2":
+			p.SetHTTP2(true)
+		case "h2c":
+			p.SetUnencryptedHTTP2(true)
+		default:
+			return nil, fmt.Errorf("unknown protocol %v, expected http1, http2 or h2c", name)
+		}
+	}
+	return &p, nil
+}
+
+func lookupConfigValue(values map[string]any, path []string) (any, bool) {
+	for i, key := range path {
+		v, ok := values[key]
+		if !ok {
+			return nil, false
+		}
+		if i == len(path)-1 {
+			return v, true
+		}
+		if values, ok = v.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// configKeys returns dot-separated paths to all the leaf values.
+func configKeys(values map[string]any, prefix string) []string {
+	var keys []string
+	for key, v := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if m, ok := v.(map[string]any); ok {
+			keys = append(keys, configKeys(m, key)...)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// configName converts a field name to snake case: MaxConnsPerIP -> max_conns_per_ip.
+func configName(name string) string {
+	rs := []rune(name)
+	var b strings.Builder
+
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			// keep plural acronyms like CIDRs together.
+			pluralAcronym := nextLower && rs[i+1] == 's' && (i+2 == len(rs) || unicode.IsUpper(rs[i+2]))
+
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (nextLower && !pluralAcronym) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func toAnySlice(ss []string) []any {
+	res := make([]any, len(ss))
+	for i, s := range ss {
+		res[i] = s
+	}
+	return res
+}
@@ -0,0 +1,161 @@
+package httpx
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerConfigValidate(t *testing.T) {
+	noProtocols := new(http.Protocols)
+
+	testCases := []struct {
+		name    string
+		cfg     ServerConfig
+		wantErr []string
+	}{
+		{
+			name: "defaults",
+			cfg:  ServerConfig{Addr: ":8080"},
+		},
+		{
+			name:    "malformed addr",
+			cfg:     ServerConfig{Addr: "localhost"},
+			wantErr: []string{`malformed Addr "localhost"`},
+		},
+		{
+			name: "contradictions",
+			cfg: ServerConfig{
+				Addr:              ":http",
+				TLSConfig:         &tls.Config{},
+				ReadHeaderTimeout: 10 * time.Second,
+				WriteTimeout:      time.Second,
+				MaxConns:          -1,
+				Protocols:         noProtocols,
+			},
+			wantErr: []string{
+				"MaxConns must not be negative",
+				"WriteTimeout (1s) is shorter than ReadHeaderTimeout (10s)",
+				"TLSConfig has no certificates",
+				"no protocols enabled",
+			},
+		},
+		{
+			name:    "untrusted proxy protocol",
+			cfg:     ServerConfig{Addr: ":8080", ProxyProtocol: &ProxyProtocolConfig{}},
+			wantErr: []string{"ProxyProtocol requires TrustedCIDRs or TrustAll"},
+		},
+		{
+			name: "queue mode",
+			cfg:  ServerConfig{Addr: ":8080", MaxConns: 10, ConnLimitMode: ConnLimitQueue, ConnQueueTimeout: time.Second},
+		},
+		{
+			name:    "queue without timeout",
+			cfg:     ServerConfig{Addr: ":8080", MaxConns: 10, ConnLimitMode: ConnLimitQueue},
+			wantErr: []string{"ConnLimitMode queue requires ConnQueueTimeout"},
+		},
+		{
+			name:    "reject with timeout",
+			cfg:     ServerConfig{Addr: ":8080", ConnLimitMode: ConnLimitReject, ConnQueueTimeout: time.Second},
+			wantErr: []string{"ConnQueueTimeout requires ConnLimitMode queue"},
+		},
+		{
+			name:    "unknown conn limit mode",
+			cfg:     ServerConfig{Addr: ":8080", ConnLimitMode: "drop"},
+			wantErr: []string{`unknown ConnLimitMode "drop"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if len(tc.wantErr) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("want error")
+			}
+			for _, want := range tc.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q must contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestServerConfigLoadEnv(t *testing.T) {
+	t.Setenv("APP_ADDR", ":9090")
+	t.Setenv("APP_READ_TIMEOUT", "10s")
+	t.Setenv("APP_MAX_CONNS_PER_IP", "5")
+	t.Setenv("APP_NO_HTTP2", "true")
+	t.Setenv("APP_PROTOCOLS", "http1,h2c")
+	t.Setenv("APP_PROXY_PROTOCOL_TRUSTED_CIDRS", "10.0.0.0/8, 192.168.0.0/16")
+	t.Setenv("APP_HTTP2_MAX_CONCURRENT_STREAMS", "100")
+
+	var cfg ServerConfig
+	if err := cfg.LoadEnv("APP_"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Addr != ":9090" || cfg.ReadTimeout != 10*time.Second || cfg.MaxConnsPerIP != 5 || !cfg.NoHTTP2 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.Protocols == nil || !cfg.Protocols.HTTP1() || !cfg.Protocols.UnencryptedHTTP2() || cfg.Protocols.HTTP2() {
+		t.Errorf("unexpected protocols: %v", cfg.Protocols)
+	}
+	if cfg.HTTP2 == nil || cfg.HTTP2.MaxConcurrentStreams != 100 {
+		t.Errorf("unexpected HTTP2: %+v", cfg.HTTP2)
+	}
+
+	wantCIDRs := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.0.0/16")}
+	if cfg.ProxyProtocol == nil || !reflect.DeepEqual(cfg.ProxyProtocol.TrustedCIDRs, wantCIDRs) {
+		t.Errorf("unexpected proxy protocol: %+v", cfg.ProxyProtocol)
+	}
+
+	t.Setenv("APP_WRITE_TIMEOUT", "15")
+	if err := cfg.LoadEnv("APP"); err == nil || !strings.Contains(err.Error(), "write_timeout") {
+		t.Errorf("want error for write_timeout, got %v", err)
+	}
+}
+
+func TestServerConfigLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"addr": ":9090",
+		"read_timeout": "10s",
+		"max_conns": 100,
+		"protocols": ["http1", "http2"],
+		"proxy_protocol": {"trusted_cidrs": ["10.0.0.0/8"], "header_timeout": "1s"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg ServerConfig
+	if err := cfg.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != ":9090" || cfg.ReadTimeout != 10*time.Second || cfg.MaxConns != 100 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.ProxyProtocol == nil || cfg.ProxyProtocol.HeaderTimeout != time.Second || len(cfg.ProxyProtocol.TrustedCIDRs) != 1 {
+		t.Errorf("unexpected proxy protocol: %+v", cfg.ProxyProtocol)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"adr": ":80"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.LoadFile(path); err == nil || !strings.Contains(err.Error(), `unknown config key "adr"`) {
+		t.Errorf("want unknown key error, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package httpx
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ConnInfo describes a connection serving a request.
+type ConnInfo struct {
+	ID         uint64    // Unique per Server.
+	AcceptedAt time.Time // When the connection was accepted.
+
+	conn  net.Conn
+	state atomic.Int64 // http.ConnState
+}
+
+// ConnInfoFromContext returns info about a connection serving the request.
+// Returns nil if the request is not served by [Server].
+func ConnInfoFromContext(ctx context.Context) *ConnInfo {
+	ci, _ := ctx.Value(connInfoKey{}).(*ConnInfo)
+	return ci
+}
+
+type connInfoKey struct{}
+
+// RemoteAddr of the connection, PROXY protocol is respected.
+func (ci *ConnInfo) RemoteAddr() net.Addr {
+	return ci.conn.RemoteAddr()
+}
+
+// LocalAddr of the connection, PROXY protocol is respected.
+func (ci *ConnInfo) LocalAddr() net.Addr {
+	return ci.conn.LocalAddr()
+}
+
+// TLS returns the TLS connection state or nil for plain connections.
+func (ci *ConnInfo) TLS() *tls.ConnectionState {
+	tc, ok := ci.conn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+	cs := tc.ConnectionState()
+	return &cs
+}
+
+// State of the connection.
+func (ci *ConnInfo) State() http.ConnState {
+	return http.ConnState(ci.state.Load())
+}
+
+// ConnStats is a snapshot of connection counters by state.
+type ConnStats struct {
+	New    int64 // Accepted but no request read yet.
+	Active int64 // Serving a request.
+	Idle   int64 // Waiting for a new request (keep-alive).
+
+	Accepted uint64 // Total connections accepted.
+	Hijacked uint64 // Total connections hijacked, they are not tracked after.
+	Closed   uint64 // Total connections closed.
+}
+
+type connCounters struct {
+	new, active, idle          atomic.Int64
+	accepted, hijacked, closed atomic.Uint64
+}
+
+// ConnStats returns live connection counters.
+func (s *Server) ConnStats() ConnStats {
+	c := &s.connStats
+	return ConnStats{
+		New:      c.new.Load(),
+		Active:   c.active.Load(),
+		Idle:     c.idle.Load(),
+		Accepted: c.accepted.Load(),
+		Hijacked: c.hijacked.Load(),
+		Closed:   c.closed.Load(),
+	}
+}
+
+func (s *Server) connContext(ctx context.Context, c net.Conn) context.Context {
+	// called from the accept loop, must not block.
+	ci := &ConnInfo{
+		ID:         s.nextConnID.Add(1),
+		AcceptedAt: time.Now(),
+		conn:       c,
+	}
+	ci.state.Store(-1)
+
+	s.conns.Store(c, ci)
+	return context.WithValue(ctx, connInfoKey{}, ci)
+}
+
+func (s *Server) connState(c net.Conn, state http.ConnState) {
+	v, ok := s.conns.Load(c)
+	if !ok {
+		return
+	}
+	ci := v.(*ConnInfo)
+
+	counters := &s.connStats
+	switch http.ConnState(ci.state.Swap(int64(state))) {
+	case http.StateNew:
+		counters.new.Add(-1)
+	case http.StateActive:
+		counters.active.Add(-1)
+	case http.StateIdle:
+		counters.idle.Add(-1)
+	}
+
+	switch state {
+	case http.StateNew:
+		counters.accepted.Add(1)
+		counters.new.Add(1)
+	case http.StateActive:
+		counters.active.Add(1)
+	case http.StateIdle:
+		counters.idle.Add(1)
+	case http.StateHijacked:
+		counters.hijacked.Add(1)
+		s.conns.Delete(c)
+	case http.StateClosed:
+		counters.closed.Add(1)
+		s.conns.Delete(c)
+	}
+
+	if s.cfg.LogConnState {
+		logger := cmp.Or(s.cfg.Logger, slog.Default())
+		logger.LogAttrs(context.Background(), slog.LevelDebug, "connection state changed",
+			slog.Uint64("conn_id", ci.ID),
+			slog.String("remote_addr", connRemoteAddr(c).String()),
+			slog.String("state", state.String()),
+		)
+	}
+}
+
+// connRemoteAddr returns the remote address without waiting for the PROXY header,
+// the proxy address is returned until the header is read.
+// ConnState is called from the accept loop for new connections, it must not block.
+func connRemoteAddr(c net.Conn) net.Addr {
+	if pc, ok := unwrapConn[*proxyConn](c); ok && !pc.done.Load() {
+		return pc.Conn.RemoteAddr()
+	}
+	return c.RemoteAddr()
+}
+
+// unwrapConn finds a connection of a given type in the listener wrappers chain.
+func unwrapConn[T net.Conn](c net.Conn) (T, bool) {
+	for {
+		if t, ok := c.(T); ok {
+			return t, true
+		}
+
+		switch cc := c.(type) {
+		case *tls.Conn:
+			c = cc.NetConn()
+		case *limitConn:
+			c = cc.Conn
+		case *proxyConn:
+			c = cc.Conn
+		default:
+			var zero T
+			return zero, false
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package httpx
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// KeyType of generated private keys.
+type KeyType int
+
+const (
+	KeyECDSA KeyType = iota // ECDSA P-256.
+	KeyRSA                  // RSA 2048.
+)
+
+func (kt KeyType) generate() (crypto.Signer, error) {
+	switch kt {
+	case KeyECDSA:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyRSA:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return nil, fmt.Errorf("httpx: unknown key type %d", kt)
+	}
+}
+
+// DevCA is a certificate authority for local development and tests.
+// Never use it in production.
+type DevCA struct {
+	Cert    *x509.Certificate
+	Key     crypto.Signer
+	keyType KeyType
+}
+
+const (
+	devCACertFile = "ca.pem"
+	devCAKeyFile  = "ca-key.pem"
+)
+
+// NewDevCA generates a new in-memory CA valid for 10 years.
+func NewDevCA(keyType KeyType) (*DevCA, error) {
+	key, err := keyType.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "httpx development CA", Organization: []string{"httpx"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: creating CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &DevCA{Cert: cert, Key: key, keyType: keyType}, nil
+}
+
+// LoadOrCreateDevCA loads CA from a directory or creates and saves a new one.
+// keyType is used for newly generated keys.
+// Useful to trust the same CA across runs.
+func LoadOrCreateDevCA(dir string, keyType KeyType) (*DevCA, error) {
+	cert, key, err := loadKeyPair(filepath.Join(dir, devCACertFile), filepath.Join(dir, devCAKeyFile))
+	switch {
+	case err == nil:
+		return &DevCA{Cert: cert, Key: key, keyType: keyType}, nil
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	ca, err := NewDevCA(keyType)
+	if err != nil {
+		return nil, err
+	}
+	if err := ca.Save(dir); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// Save CA certificate and key as PEM files to a directory.
+func (ca *DevCA) Save(dir string) error {
+	return saveKeyPair(dir, devCACertFile, devCAKeyFile, [][]byte{ca.Cert.Raw}, ca.Key)
+}
+
+// Issue a leaf certificate valid for 1 year for given hosts.
+// Hosts are DNS names, IP addresses, URIs (like spiffe://example.org/service) or emails.
+// The first host is used as a subject common name.
+// Certificate is valid both for servers and clients.
+func (ca *DevCA) Issue(hosts ...string) (*tls.Certificate, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("httpx: at least one host is required")
+	}
+
+	key, err := ca.keyType.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: hosts[0], Organization: []string{"httpx development"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if ca.keyType == KeyRSA {
+		tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	for _, h := range hosts {
+		switch {
+		case net.ParseIP(h) != nil:
+			tmpl.IPAddresses = append(tmpl.IPAddresses, net.ParseIP(h))
+		case strings.Contains(h, "://"):
+			u, err := url.Parse(h)
+			if err != nil {
+				return nil, fmt.Errorf("httpx: malformed URI %q: %w", h, err)
+			}
+			tmpl.URIs = append(tmpl.URIs, u)
+		case strings.Contains(h, "@"):
+			tmpl.EmailAddresses = append(tmpl.EmailAddresses, h)
+		default:
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: creating certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der, ca.Cert.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// LoadOrIssue loads a leaf certificate from a directory or issues and saves a new one.
+// Files are named after the first host. Certificate is reissued if it has expired,
+// is not signed by this CA or does not cover all the hosts.
+func (ca *DevCA) LoadOrIssue(dir string, hosts ...string) (*tls.Certificate, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("httpx: at least one host is required")
+	}
+
+	name := strings.NewReplacer("*", "_wildcard", ":", "_", "/", "_").Replace(hosts[0])
+	certFile, keyFile := name+".pem", name+"-key.pem"
+
+	cert, key, err := loadKeyPair(filepath.Join(dir, certFile), filepath.Join(dir, keyFile))
+	switch {
+	case err == nil:
+		if ca.covers(cert, hosts) {
+			return &tls.Certificate{
+				Certificate: [][]byte{cert.Raw, ca.Cert.Raw},
+				PrivateKey:  key,
+				Leaf:        cert,
+			}, nil
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	leaf, err := ca.Issue(hosts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveKeyPair(dir, certFile, keyFile, leaf.Certificate, leaf.PrivateKey.(crypto.Signer)); err != nil {
+		return nil, err
+	}
+	return leaf, nil
+}
+
+func (ca *DevCA) covers(cert *x509.Certificate, hosts []string) bool {
+	if time.Now().After(cert.NotAfter) || cert.CheckSignatureFrom(ca.Cert) != nil {
+		return false
+	}
+
+	var names []string
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			h = ip.String()
+		}
+		if !slices.Contains(names, h) {
+			return false
+		}
+	}
+	return true
+}
+
+// TLSConfig returns a server config with a certificate for given hosts.
+// Ready to be used in [ServerConfig.TLSConfig].
+func (ca *DevCA) TLSConfig(hosts ...string) (*tls.Config, error) {
+	cert, err := ca.Issue(hosts...)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// CertPool returns a pool with the CA certificate.
+func (ca *DevCA) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return pool
+}
+
+// Transport returns [NewPooledTransport] which trusts only this CA.
+func (ca *DevCA) Transport() *http.Transport {
+	transport := NewPooledTransport()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    ca.CertPool(),
+		MinVersion: tls.VersionTLS12,
+	}
+	return transport
+}
+
+func newSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("httpx: generating serial number: %w", err)
+	}
+	return serial, nil
+}
+
+func loadKeyPair(certFile, keyFile string) (*x509.Certificate, crypto.Signer, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, fmt.Errorf("httpx: no certificate in %s", certFile)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("httpx: parsing %s: %w", certFile, err)
+	}
+
+	block, _ = pem.Decode(keyPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, nil, fmt.Errorf("httpx: no private key in %s", keyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("httpx: parsing %s: %w", keyFile, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("httpx: unsupported private key in %s", keyFile)
+	}
+	return cert, signer, nil
+}
+
+func saveKeyPair(dir, certFile, keyFile string, chain [][]byte, key crypto.Signer) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("httpx: marshaling private key: %w", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	if err := os.WriteFile(filepath.Join(dir, certFile), certPEM, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, keyFile), keyPEM, 0o600)
+}
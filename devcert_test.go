@@ -0,0 +1,81 @@
+package httpx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDevCA(t *testing.T) {
+	for _, kt := range []KeyType{KeyECDSA, KeyRSA} {
+		ca, err := NewDevCA(kt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tlsConfig, err := ca.TLSConfig("localhost", "127.0.0.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Proto))
+		}))
+		ts.TLS = tlsConfig
+		ts.StartTLS()
+
+		client := &http.Client{Transport: ca.Transport()}
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		ts.Close()
+
+		if string(body) != "HTTP/1.1" && string(body) != "HTTP/2.0" {
+			t.Errorf("unexpected body: %q", body)
+		}
+
+		if _, err := NewClient().Get(ts.URL); err == nil {
+			t.Error("dev CA must not be trusted by default")
+		}
+	}
+}
+
+func TestDevCAPersist(t *testing.T) {
+	dir := t.TempDir()
+
+	ca1, err := LoadOrCreateDevCA(dir, KeyECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca2, err := LoadOrCreateDevCA(dir, KeyECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ca1.Cert.Equal(ca2.Cert) {
+		t.Fatal("CA must be reused")
+	}
+
+	leaf1, err := ca1.LoadOrIssue(dir, "localhost", "::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf2, err := ca2.LoadOrIssue(dir, "localhost", "::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !leaf1.Leaf.Equal(leaf2.Leaf) {
+		t.Error("certificate must be reused")
+	}
+
+	leaf3, err := ca2.LoadOrIssue(dir, "localhost", "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf1.Leaf.Equal(leaf3.Leaf) {
+		t.Error("certificate must be reissued for new hosts")
+	}
+}
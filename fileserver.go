@@ -0,0 +1,278 @@
+package httpx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FileServerConfig configures [NewFileServer].
+type FileServerConfig struct {
+	// SPA serves index.html for not found paths without an extension,
+	// so client-side routes of single-page apps are handled by the app.
+	SPA bool
+
+	// Immutable reports whether a file never changes and can be cached forever.
+	// Default treats names with a hex hash of 8 or more chars like app.3f2a9c1b.js as immutable.
+	Immutable func(name string) bool
+
+	// NotFound handles requests without a file, like [Router.NotFound].
+	// Default is 404 Not Found rendered by [ErrorResponse].
+	NotFound http.Handler
+
+	// MethodNotAllowed handles requests other than GET and HEAD, like [Router.MethodNotAllowed].
+	// Allow header is set before the handler is called.
+	// Default is 405 Method Not Allowed rendered by [ErrorResponse].
+	MethodNotAllowed http.Handler
+}
+
+type staticFile struct {
+	name        string
+	contentType string
+	etag        string
+	immutable   bool
+	gzip        *staticFile // precompressed sibling, if any.
+}
+
+// NewFileServer returns a handler serving files of fsys like [embed.FS] or [os.DirFS].
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	static, _ := fs.Sub(dist, "dist")
+//	h, err := httpx.NewFileServer(static, httpx.FileServerConfig{SPA: true})
+//	r.Mount("/app", h)
+//
+// Files are read once to compute strong ETags, they must not change after that.
+// Content type is set by [ContentTypeByExt]. If a file has a .gz sibling it's served
+// to clients accepting gzip encoding. Range and conditional requests are supported.
+// Directories are not listed, index.html is served for a directory instead,
+// a directory path without a trailing slash is redirected like in [http.FileServer].
+// Immutable files get a 1 year cache, other files are revalidated with ETag.
+func NewFileServer(fsys fs.FS, cfg FileServerConfig) (http.Handler, error) {
+	immutable := cfg.Immutable
+	if immutable == nil {
+		immutable = isHashedName
+	}
+
+	files := map[string]*staticFile{}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		etag, err := fileETag(fsys, name)
+		if err != nil {
+			return err
+		}
+		files[name] = &staticFile{
+			name:        name,
+			contentType: ContentTypeByExt(name),
+			etag:        etag,
+			immutable:   immutable(name),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, f := range files {
+		if gz, ok := files[name+".gz"]; ok {
+			f.gzip = gz
+		}
+	}
+
+	notFound := cfg.NotFound
+	if notFound == nil {
+		notFound = statusHandler(http.StatusNotFound)
+	}
+	methodNotAllowed := cfg.MethodNotAllowed
+	if methodNotAllowed == nil {
+		methodNotAllowed = statusHandler(http.StatusMethodNotAllowed)
+	}
+
+	return &fileServer{
+		fsys:             fsys,
+		files:            files,
+		spa:              cfg.SPA,
+		notFound:         notFound,
+		methodNotAllowed: methodNotAllowed,
+	}, nil
+}
+
+type fileServer struct {
+	fsys             fs.FS
+	files            map[string]*staticFile
+	spa              bool
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		s.methodNotAllowed.ServeHTTP(w, r)
+		return
+	}
+
+	f, dir := s.lookup(r.URL.Path)
+	if f == nil {
+		s.notFound.ServeHTTP(w, r)
+		return
+	}
+	if dir && !strings.HasSuffix(r.URL.Path, "/") {
+		// relative to work under a mount prefix, like http.FileServer.
+		target := path.Base(r.URL.Path) + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		w.Header().Set("Location", target)
+		w.WriteHeader(http.StatusMovedPermanently)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", f.contentType)
+	if f.immutable {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		h.Set("Cache-Control", "no-cache")
+	}
+
+	if f.gzip != nil {
+		h.Add("Vary", "Accept-Encoding")
+		if acceptsEncoding(r, "gzip") {
+			h.Set("Content-Encoding", "gzip")
+			f = f.gzip
+		}
+	}
+	h.Set("ETag", f.etag)
+
+	if err := s.serveFile(w, r, f); err != nil {
+		// error must not be cached like the file.
+		h.Del("Cache-Control")
+		h.Del("Content-Encoding")
+		h.Del("ETag")
+		ErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+// lookup a file for a request path, nil if not found.
+// dir reports whether the file is index.html of a directory.
+func (s *fileServer) lookup(urlPath string) (f *staticFile, dir bool) {
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if f, ok := s.files[name]; ok {
+		return f, false
+	}
+	if f, ok := s.files[path.Join(name, "index.html")]; ok {
+		return f, name != ""
+	}
+	if s.spa && path.Ext(name) == "" {
+		return s.files["index.html"], false
+	}
+	return nil, false
+}
+
+func statusHandler(code int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ErrorResponse(w, code, errors.New(http.StatusText(code)))
+	})
+}
+
+func (s *fileServer) serveFile(w http.ResponseWriter, r *http.Request, f *staticFile) error {
+	file, err := s.fsys.Open(f.name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		raw, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(raw)
+	}
+	http.ServeContent(w, r, f.name, stat.ModTime(), content)
+	return nil
+}
+
+// fileETag returns a strong ETag from a hash of the file content.
+func fileETag(fsys fs.FS, name string) (string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return `"` + base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:18]) + `"`, nil
+}
+
+// isHashedName reports whether a part of the file name looks like a content hash:
+// 8 or more lowercase hex chars with at least one digit and one letter.
+func isHashedName(name string) bool {
+	base := path.Base(name)
+	base = strings.TrimSuffix(base, path.Ext(base))
+
+	parts := strings.FieldsFunc(base, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	})
+	for _, part := range parts[min(1, len(parts)):] {
+		if len(part) >= 8 && isHash(part) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHash(s string) bool {
+	hasDigit, hasLetter := false, false
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		case c >= 'a' && c <= 'f':
+			hasLetter = true
+		default:
+			return false
+		}
+	}
+	return hasDigit && hasLetter
+}
+
+// acceptsEncoding reports whether the encoding is accepted by Accept-Encoding header with non-zero quality.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, accept := range r.Header.Values("Accept-Encoding") {
+		for part := range strings.SplitSeq(accept, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.TrimSpace(coding)
+			if !strings.EqualFold(coding, encoding) && coding != "*" {
+				continue
+			}
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
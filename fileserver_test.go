@@ -0,0 +1,228 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileServer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":                 {Data: []byte("<html>app</html>")},
+		"assets/app.3f2a9c1b.js":     {Data: []byte("console.log(1)")},
+		"assets/app.3f2a9c1b.js.gz":  {Data: []byte("gzipped")},
+		"assets/logo.png":            {Data: []byte("png")},
+		"docs/index.html":            {Data: []byte("<html>docs</html>")},
+		"files/report-2024.txt":      {Data: []byte("0123456789")},
+		"files/nested/readme.txt":    {Data: []byte("readme")},
+		"assets/index-BzX8f2K1.css":  {Data: []byte("body{}")},
+		"assets/vendor-abcdefgh.css": {Data: []byte("p{}")},
+		"assets/app.wasm":            {Data: []byte("wasm")},
+	}
+
+	h, err := NewFileServer(fsys, FileServerConfig{SPA: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Method     string
+		Path       string
+		Header     map[string]string
+		WantStatus int
+		WantBody   string
+		WantHeader map[string]string
+	}{
+		{
+			"GET", "/", nil, http.StatusOK, "<html>app</html>",
+			map[string]string{"Content-Type": "text/html; charset=utf-8", "Cache-Control": "no-cache"},
+		},
+		{
+			"GET", "/assets/app.3f2a9c1b.js", nil, http.StatusOK, "console.log(1)",
+			map[string]string{
+				"Content-Type":     "text/javascript; charset=utf-8",
+				"Cache-Control":    "public, max-age=31536000, immutable",
+				"Vary":             "Accept-Encoding",
+				"Content-Encoding": "",
+			},
+		},
+		{
+			"GET", "/assets/app.3f2a9c1b.js", map[string]string{"Accept-Encoding": "br, gzip"}, http.StatusOK, "gzipped",
+			map[string]string{"Content-Type": "text/javascript; charset=utf-8", "Content-Encoding": "gzip"},
+		},
+		{
+			"GET", "/assets/app.3f2a9c1b.js", map[string]string{"Accept-Encoding": "gzip;q=0"}, http.StatusOK, "console.log(1)",
+			map[string]string{"Content-Encoding": ""},
+		},
+		{
+			"GET", "/assets/index-BzX8f2K1.css", nil, http.StatusOK, "body{}",
+			map[string]string{"Cache-Control": "no-cache"},
+		},
+		{
+			"GET", "/assets/vendor-abcdefgh.css", nil, http.StatusOK, "p{}",
+			map[string]string{"Cache-Control": "no-cache", "Vary": ""},
+		},
+		{
+			"GET", "/files/report-2024.txt", map[string]string{"Range": "bytes=2-4"}, http.StatusPartialContent, "234",
+			map[string]string{"Content-Range": "bytes 2-4/10"},
+		},
+		{"HEAD", "/assets/logo.png", nil, http.StatusOK, "", map[string]string{"Content-Type": "image/png"}},
+		{"GET", "/assets/app.wasm", nil, http.StatusOK, "wasm", map[string]string{"Content-Type": "application/wasm"}},
+		{"GET", "/docs/", nil, http.StatusOK, "<html>docs</html>", nil},
+		{"GET", "/docs", nil, http.StatusMovedPermanently, "", map[string]string{"Location": "docs/"}},
+		{"GET", "/docs?page=2", nil, http.StatusMovedPermanently, "", map[string]string{"Location": "docs/?page=2"}},
+		{"GET", "/files/nested/", nil, http.StatusOK, "<html>app</html>", nil},
+		{"GET", "/users/42/settings", nil, http.StatusOK, "<html>app</html>", nil},
+		{"GET", "/assets/missing.js", nil, http.StatusNotFound, "", nil},
+		{"GET", "/../index.html", nil, http.StatusOK, "<html>app</html>", nil},
+		{"POST", "/index.html", nil, http.StatusMethodNotAllowed, "", map[string]string{"Allow": "GET, HEAD"}},
+	}
+
+	for _, test := range tests {
+		rq := httptest.NewRequest(test.Method, "http://example.com"+test.Path, nil)
+		for k, v := range test.Header {
+			rq.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, rq)
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s %s: status want %d; have %d", test.Method, test.Path, test.WantStatus, rr.Code)
+		}
+		if test.WantBody != "" && rr.Body.String() != test.WantBody {
+			t.Errorf("%s %s: body want %q; have %q", test.Method, test.Path, test.WantBody, rr.Body.String())
+		}
+		for k, want := range test.WantHeader {
+			if have := rr.Header().Get(k); have != want {
+				t.Errorf("%s %s: %s want %q; have %q", test.Method, test.Path, k, want, have)
+			}
+		}
+	}
+}
+
+func TestFileServerETag(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":    {Data: []byte("console.log(1)")},
+		"app.js.gz": {Data: []byte("gzipped")},
+	}
+
+	h, err := NewFileServer(fsys, FileServerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(header map[string]string) *httptest.ResponseRecorder {
+		rq := httptest.NewRequest("GET", "/app.js", nil)
+		for k, v := range header {
+			rq.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, rq)
+		return rr
+	}
+
+	plain := get(nil).Header().Get("ETag")
+	gzipped := get(map[string]string{"Accept-Encoding": "gzip"}).Header().Get("ETag")
+	if plain == "" || plain == gzipped || plain[0] != '"' {
+		t.Fatalf("want different strong etags; have %q and %q", plain, gzipped)
+	}
+
+	if rr := get(map[string]string{"If-None-Match": plain}); rr.Code != http.StatusNotModified {
+		t.Errorf("if-none-match: want %d; have %d", http.StatusNotModified, rr.Code)
+	}
+	if rr := get(map[string]string{"If-None-Match": gzipped}); rr.Code != http.StatusOK {
+		t.Errorf("if-none-match of another encoding: want %d; have %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestFileServerNoSPA(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte("<html>app</html>")},
+		"img/logo.png":  {Data: []byte("png")},
+		"img/photo.jpg": {Data: []byte("jpg")},
+	}
+
+	h, err := NewFileServer(fsys, FileServerConfig{
+		Immutable: func(name string) bool { return name == "img/logo.png" },
+		NotFound: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusNotFound)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Path       string
+		WantStatus int
+		WantCache  string
+	}{
+		{"/", http.StatusOK, "no-cache"},
+		{"/img/", http.StatusNotFound, "no-store"},
+		{"/users/42", http.StatusNotFound, "no-store"},
+		{"/img/logo.png", http.StatusOK, "public, max-age=31536000, immutable"},
+		{"/img/photo.jpg", http.StatusOK, "no-cache"},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", test.Path, nil))
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s: status want %d; have %d", test.Path, test.WantStatus, rr.Code)
+		}
+		if cache := rr.Header().Get("Cache-Control"); cache != test.WantCache {
+			t.Errorf("%s: cache want %q; have %q", test.Path, test.WantCache, cache)
+		}
+	}
+}
+
+func TestIsHashedName(t *testing.T) {
+	tests := []struct {
+		Name string
+		Want bool
+	}{
+		{"assets/app.3f2a9c1b.js", true},
+		{"chunk-5d41402abc4b2a76.js", true},
+		{"app.3f2a9c1b.min.js", true},
+		{"3f2a9c1b.js", false},
+		{"favicon-20240101.png", false},
+		{"user-guide-chapter10.pdf", false},
+		{"backup_v20231231.zip", false},
+		{"index-CwRHzmGk.js", false},
+		{"vendor-abcdefgh.css", false},
+		{"app.DEADBEEF1.js", false},
+	}
+
+	for _, test := range tests {
+		if have := isHashedName(test.Name); have != test.Want {
+			t.Errorf("%s: want %v; have %v", test.Name, test.Want, have)
+		}
+	}
+}
+
+func TestFileServerOpenError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.3f2a9c1b.js": {Data: []byte("console.log(1)")},
+	}
+
+	h, err := NewFileServer(fsys, FileServerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(fsys, "app.3f2a9c1b.js")
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/app.3f2a9c1b.js", nil))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("status want %d; have %d", http.StatusInternalServerError, rr.Code)
+	}
+	for _, k := range []string{"Cache-Control", "ETag"} {
+		if v := rr.Header().Get(k); v != "" {
+			t.Errorf("%s must not be set; have %q", k, v)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+)
+
+// Handler adapts a typed function to [http.Handler].
+//
+// Request body is decoded with [UnmarshalRequest] when present, form bodies are
+// decoded by [Bind] for types with form fields. Then request values are set with [Bind]
+// and checked with [Validate]:
+//
+//	type GetUser struct {
+//		ID     int64  `json:"-" path:"id" validate:"min=1"`
+//		Fields string `json:"-" query:"fields"`
+//	}
+//
+// Returned [*Error] is rendered with its code, other errors with 500 Internal Server Error.
+// Result is encoded with [MarshalResponse] and 200 OK.
+// Request and response types are added to [RouteMeta] when registered in [Router].
+// Panics if validate tags of the request type are malformed.
+func Handler[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) http.Handler {
+	if err := checkValidateTags(reflect.TypeFor[Req]()); err != nil {
+		panic(err)
+	}
+	return typedHandler[Req, Resp](fn)
+}
+
+type typedHandler[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+func (h typedHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	HandlerFunc(h.serve).ServeHTTP(w, r)
+}
+
+func (h typedHandler[Req, Resp]) serveError(w http.ResponseWriter, r *http.Request) error {
+	return h.serve(w, r)
+}
+
+func (h typedHandler[Req, Resp]) serve(w http.ResponseWriter, r *http.Request) error {
+	var req Req
+	hasBody := r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+	// form body is decoded by Bind.
+	if hasBody && !(isFormRequest(r) && hasFormFields(reflect.TypeFor[Req]())) {
+		if code, err := UnmarshalRequest(w, r, &req); err != nil {
+			return &Error{Code: code, Message: err.Error()}
+		}
+	}
+	if isStruct(reflect.TypeFor[Req]()) {
+		if err := Bind(r, &req); err != nil {
+			return err
+		}
+		if err := Validate(req); err != nil {
+			return err
+		}
+	}
+
+	resp, err := h(r.Context(), req)
+	if err != nil {
+		return err
+	}
+	MarshalResponse(w, http.StatusOK, resp)
+	return nil
+}
+
+// routeMeta fills types of the handler if they are not set.
+func (h typedHandler[Req, Resp]) routeMeta(meta *RouteMeta) {
+	reqType := reflect.TypeFor[Req]()
+	params, hasBody := requestParams(reqType)
+
+	if meta.Request == nil && hasBody {
+		meta.Request = reqType
+	}
+	if meta.Responses == nil {
+		meta.Responses = map[int]reflect.Type{http.StatusOK: reflect.TypeFor[Resp]()}
+	}
+	for _, p := range params {
+		if !hasParam(meta.Params, p) {
+			meta.Params = append(meta.Params, p)
+		}
+	}
+}
+
+func hasParam(params []Param, p Param) bool {
+	for _, have := range params {
+		if have.In == p.In && have.Name == p.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusClientClosedRequest is a non-standard status code
+// used to log requests canceled by the client.
+const StatusClientClosedRequest = 499
+
+// HandlerFunc is an HTTP handler returning an error.
+// When registered in [Router] errors are rendered by [Router.ErrorHandler],
+// otherwise like the default one.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP implements [http.Handler].
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h(w, r); err != nil {
+		defaultErrorHandler(nil, ErrorFormatAuto)(w, r, err)
+	}
+}
+
+func (h HandlerFunc) serveError(w http.ResponseWriter, r *http.Request) error {
+	return h(w, r)
+}
+
+// errorServer is implemented by handlers returning errors.
+type errorServer interface {
+	serveError(w http.ResponseWriter, r *http.Request) error
+}
+
+type errorMapping struct {
+	target error
+	code   int
+}
+
+// errorStatus returns status code for an error: from the mappings, [Error] code,
+// [StatusClientClosedRequest] for canceled requests or 500 Internal Server Error.
+func errorStatus(mappings []errorMapping, r *http.Request, err error) int {
+	for _, m := range mappings {
+		if errors.Is(err, m.target) {
+			return m.code
+		}
+	}
+
+	if e, ok := asError(err); ok && e.Code != 0 {
+		return e.Code
+	}
+	if errors.Is(err, context.Canceled) && r.Context().Err() != nil {
+		return StatusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// defaultErrorHandler renders errors with [ErrorResponse] or [ProblemResponse].
+// Messages of unknown errors are not exposed, such errors are logged.
+// Nothing is written for requests canceled by the client.
+func defaultErrorHandler(mappings []errorMapping, format ErrorFormat) func(w http.ResponseWriter, r *http.Request, err error) {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		code := errorStatus(mappings, r, err)
+		if code == StatusClientClosedRequest {
+			slog.InfoContext(r.Context(), "httpx: client closed request",
+				"status", code, "method", r.Method, "path", r.URL.Path)
+			return
+		}
+
+		var p *Problem
+		switch e, ok := asError(err); {
+		case errors.As(err, &p) && p != nil:
+			copied := *p
+			copied.Status = code
+			err = &copied
+		case ok:
+			e.Code = code
+			err = e
+		case code == http.StatusInternalServerError:
+			slog.ErrorContext(r.Context(), "httpx: handler error",
+				"method", r.Method, "path", r.URL.Path, "error", err)
+			err = &Error{Code: code, Message: http.StatusText(code)}
+		default:
+			err = &Error{Code: code, Message: err.Error()}
+		}
+		renderError(w, r, code, err, format)
+	}
+}
@@ -0,0 +1,212 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	type UpdateUser struct {
+		ID     int64  `json:"-" path:"id"`
+		DryRun bool   `json:"-" query:"dry_run"`
+		Tenant string `json:"-" header:"X-Tenant"`
+		Name   string `json:"name"`
+	}
+	type User struct {
+		ID     int64  `json:"id"`
+		Tenant string `json:"tenant"`
+		Name   string `json:"name"`
+	}
+
+	r := NewRouter()
+	r.Handle("PUT /users/{id}", Handler(func(ctx context.Context, req UpdateUser) (User, error) {
+		switch {
+		case req.ID == 404:
+			return User{}, &Error{Code: http.StatusNotFound, Message: "user not found"}
+		case req.ID == 500:
+			return User{}, errors.New("database is down")
+		}
+		return User{ID: req.ID, Tenant: req.Tenant, Name: req.Name}, nil
+	}))
+
+	tests := []struct {
+		Path       string
+		Body       string
+		WantStatus int
+		WantBody   string
+	}{
+		{"/users/1?dry_run=true", `{"name":"Ann"}`, http.StatusOK, `{"id":1,"tenant":"acme","name":"Ann"}`},
+		{"/users/abc", `{"name":"Ann"}`, http.StatusBadRequest, `"msg": "invalid value \"abc\": invalid syntax"`},
+		{"/users/1?dry_run=maybe", `{}`, http.StatusBadRequest, `"field": "dry_run"`},
+		{"/users/1", `{"age":1}`, http.StatusBadRequest, `unknown field`},
+		{"/users/404", `{}`, http.StatusNotFound, `"msg": "user not found"`},
+		{"/users/500", `{}`, http.StatusInternalServerError, `"msg": "Internal Server Error"`},
+	}
+
+	for _, test := range tests {
+		rq := httptest.NewRequest("PUT", test.Path, strings.NewReader(test.Body))
+		rq.Header.Set("Content-Type", "application/json")
+		rq.Header.Set("X-Tenant", "acme")
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, rq)
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s: status want %d; have %d", test.Path, test.WantStatus, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, test.WantBody) {
+			t.Errorf("%s: body want %q; have %q", test.Path, test.WantBody, body)
+		}
+	}
+
+	meta := r.Routes()[0].Meta
+	wantMeta := RouteMeta{
+		Request:   reflect.TypeFor[UpdateUser](),
+		Responses: map[int]reflect.Type{http.StatusOK: reflect.TypeFor[User]()},
+		Params: []Param{
+			{Name: "id", In: "path", Type: reflect.TypeFor[int64](), Required: true},
+			{Name: "dry_run", In: "query", Type: reflect.TypeFor[bool]()},
+			{Name: "X-Tenant", In: "header", Type: reflect.TypeFor[string]()},
+		},
+	}
+	if !reflect.DeepEqual(meta, wantMeta) {
+		t.Errorf("meta\nwant %+v\nhave %+v", wantMeta, meta)
+	}
+}
+
+func TestHandlerForm(t *testing.T) {
+	type Login struct {
+		Next     string `query:"next"`
+		Username string `form:"username" validate:"required"`
+		Remember bool   `form:"remember"`
+	}
+
+	r := NewRouter()
+	r.Handle("POST /login", Handler(func(ctx context.Context, req Login) (Login, error) {
+		return req, nil
+	}))
+
+	multipartBody := "--b\r\nContent-Disposition: form-data; name=\"username\"\r\n\r\nbob\r\n--b--\r\n"
+
+	tests := []struct {
+		ContentType string
+		Body        string
+		WantStatus  int
+		WantBody    string
+	}{
+		{"application/x-www-form-urlencoded", "username=ann&remember=true", http.StatusOK, `{"Next":"/home","Username":"ann","Remember":true}`},
+		{"multipart/form-data; boundary=b", multipartBody, http.StatusOK, `{"Next":"/home","Username":"bob","Remember":false}`},
+		{"application/x-www-form-urlencoded", "remember=true", http.StatusBadRequest, `"field": "username"`},
+		{"text/plain", "username=ann", http.StatusUnsupportedMediaType, `not application/json`},
+	}
+
+	for _, test := range tests {
+		rq := httptest.NewRequest("POST", "/login?next=/home", strings.NewReader(test.Body))
+		rq.Header.Set("Content-Type", test.ContentType)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, rq)
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s: status want %d; have %d", test.ContentType, test.WantStatus, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, test.WantBody) {
+			t.Errorf("%s: body want %q; have %q", test.ContentType, test.WantBody, body)
+		}
+	}
+}
+
+func TestHandlerFunc(t *testing.T) {
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	errNotFound := errors.New("not found")
+
+	r := NewRouter()
+	r.MapError(errNotFound, http.StatusNotFound)
+	r.Handle("GET /mapped", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("user 1: %w", errNotFound)
+	}))
+	r.Handle("GET /value", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return Error{Code: http.StatusConflict, Message: "already exists"}
+	}))
+	r.Handle("GET /wrapped", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("wrapped: %w", &Error{Code: http.StatusForbidden, Message: "denied"})
+	}))
+	r.Handle("GET /unknown", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("database password is 123")
+	}))
+	r.Handle("GET /canceled", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return r.Context().Err()
+	}))
+	r.Handle("GET /ok", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		ReturnOKJSON(w, "ok")
+		return nil
+	}))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		Path       string
+		Ctx        context.Context
+		WantStatus int
+		WantBody   string
+	}{
+		{"/mapped", nil, http.StatusNotFound, `"msg": "user 1: not found"`},
+		{"/value", nil, http.StatusConflict, `"msg": "already exists"`},
+		{"/wrapped", nil, http.StatusForbidden, `"msg": "denied"`},
+		{"/unknown", nil, http.StatusInternalServerError, `"msg": "Internal Server Error"`},
+		{"/canceled", canceled, http.StatusOK, ``},
+		{"/ok", nil, http.StatusOK, `"ok"`},
+	}
+
+	for _, test := range tests {
+		rq := httptest.NewRequest("GET", test.Path, nil)
+		if test.Ctx != nil {
+			rq = rq.WithContext(test.Ctx)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, rq)
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s: status want %d; have %d", test.Path, test.WantStatus, rr.Code)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, test.WantBody) || (test.WantBody == "" && body != "") {
+			t.Errorf("%s: body want %q; have %q", test.Path, test.WantBody, body)
+		}
+	}
+
+	var have int
+	r.ErrorHandler(func(w http.ResponseWriter, req *http.Request, err error) {
+		have = r.ErrorStatus(req, err)
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/mapped", nil))
+	if rr.Code != http.StatusTeapot || have != http.StatusNotFound {
+		t.Errorf("custom error handler: have %d and %d", rr.Code, have)
+	}
+}
+
+func TestHandlerInvalidTags(t *testing.T) {
+	type Req struct {
+		Name string `json:"name" validate:"min=x"`
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("want panic for malformed validate tag")
+		}
+	}()
+	Handler(func(ctx context.Context, req Req) (Req, error) { return req, nil })
+}
@@ -0,0 +1,144 @@
+package httpx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type hostRoute struct {
+	pattern   string
+	labels    []string
+	wildcards int
+	router    *Router
+}
+
+type hostValuesKey struct{}
+
+// Host creates a router for requests with a matching host, like "api.example.com".
+// Labels in braces are wildcards matching a single label, like "{tenant}.example.com",
+// use [HostValue] to get their values.
+// Exact hosts take precedence over patterns with wildcards.
+//
+// If a host router has no route for the path, the request is served by other
+// matching host routers or by the routes of the current router.
+//
+// Host router has its own middlewares, not found and error handling are shared.
+// Global middlewares of the current router are applied before host routers,
+// route middlewares of a subrouter become global middlewares of the host router.
+// Prefix of a subrouter created by [Router.Route] is added to the host router patterns.
+func (r *Router) Host(pattern string, fn func(r *Router)) {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	if pattern == "" || strings.Contains(pattern, "/") {
+		panic("httpx: invalid host pattern " + pattern)
+	}
+	if _, host, _ := splitPattern(r.prefix); host != "" {
+		panic("httpx: host pattern " + pattern + " inside of a host prefix " + r.prefix)
+	}
+
+	hr := &hostRoute{
+		pattern: pattern,
+		labels:  strings.Split(pattern, "."),
+		router:  NewRouter(),
+	}
+	for _, label := range hr.labels {
+		if isHostWildcard(label) {
+			hr.wildcards++
+		}
+	}
+	hr.router.config = r.config
+	hr.router.prefix = r.prefix
+	hr.router.globalMw = slices.Clone(r.routeMw)
+	fn(hr.router)
+
+	// keep more specific patterns first, stable for the same number of wildcards.
+	i, _ := slices.BinarySearchFunc(*r.hosts, hr, func(a, b *hostRoute) int {
+		if a.wildcards <= b.wildcards {
+			return -1
+		}
+		return 1
+	})
+	*r.hosts = slices.Insert(*r.hosts, i, hr)
+}
+
+// HostValue returns a value of the host wildcard set by [Router.Host].
+func HostValue(r *http.Request, name string) string {
+	values, _ := r.Context().Value(hostValuesKey{}).(map[string]string)
+	return values[name]
+}
+
+func (r *Router) serveHost(w http.ResponseWriter, req *http.Request) bool {
+	if len(*r.hosts) == 0 {
+		return false
+	}
+
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	labels := strings.Split(host, ".")
+
+	for _, hr := range *r.hosts {
+		values, ok := hr.match(labels)
+		if !ok {
+			continue
+		}
+		if !hr.router.hasRoute(req) {
+			continue
+		}
+
+		ctx := req.Context()
+		if len(values) > 0 {
+			if outer, ok := ctx.Value(hostValuesKey{}).(map[string]string); ok {
+				for k, v := range outer {
+					if _, ok := values[k]; !ok {
+						values[k] = v
+					}
+				}
+			}
+			ctx = context.WithValue(ctx, hostValuesKey{}, values)
+		}
+
+		// report host in the pattern, see mountedPattern.
+		mount := hr.pattern
+		if outer, ok := ctx.Value(mountKey{}).(string); ok {
+			mount = joinPattern(outer, mount)
+		}
+		ctx = context.WithValue(ctx, mountKey{}, mount)
+
+		hr.router.ServeHTTP(w, req.WithContext(ctx))
+		return true
+	}
+	return false
+}
+
+func (hr *hostRoute) match(labels []string) (map[string]string, bool) {
+	if len(labels) != len(hr.labels) {
+		return nil, false
+	}
+
+	var values map[string]string
+	for i, label := range hr.labels {
+		if !isHostWildcard(label) {
+			if label != labels[i] {
+				return nil, false
+			}
+			continue
+		}
+		if labels[i] == "" {
+			return nil, false
+		}
+		if values == nil {
+			values = map[string]string{}
+		}
+		values[label[1:len(label)-1]] = labels[i]
+	}
+	return values, true
+}
+
+func isHostWildcard(label string) bool {
+	return len(label) > 2 && label[0] == '{' && label[len(label)-1] == '}'
+}
@@ -0,0 +1,131 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterHost(t *testing.T) {
+	used := ""
+	mw := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				used += name
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	hf := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s", r.Pattern, HostValue(r, "tenant"))
+	}
+
+	r := NewRouter()
+	r.Use(mw("g"))
+	r.HandleFunc("GET /", hf)
+
+	r.Host("{tenant}.example.com", func(r *Router) {
+		r.Use(mw("t"))
+		r.HandleFunc("GET /users/{id}", hf)
+	})
+	r.Host("api.example.com", func(r *Router) {
+		r.Use(mw("a"))
+		r.HandleFunc("GET /users/{id}", hf)
+	})
+
+	tests := []struct {
+		URL        string
+		WantStatus int
+		WantBody   string
+		WantUsed   string
+	}{
+		{"http://acme.example.com/users/1", http.StatusOK, "GET {tenant}.example.com/users/{id}|acme", "gt"},
+		{"http://ACME.example.com:8080/users/1", http.StatusOK, "GET {tenant}.example.com/users/{id}|acme", "gt"},
+		{"http://api.example.com/users/1", http.StatusOK, "GET api.example.com/users/{id}|", "ga"},
+		{"http://acme.example.com/nope", http.StatusOK, "GET /|", "g"},
+		{"http://api.example.com/nope", http.StatusOK, "GET /|", "g"},
+		{"http://a.b.example.com/users/1", http.StatusOK, "GET /|", "g"},
+		{"http://example.com/", http.StatusOK, "GET /|", "g"},
+	}
+
+	for _, test := range tests {
+		used = ""
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", test.URL, nil))
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s: status want %d; have %d", test.URL, test.WantStatus, rr.Code)
+		}
+		if test.WantBody != "" && rr.Body.String() != test.WantBody {
+			t.Errorf("%s: body want %q; have %q", test.URL, test.WantBody, rr.Body.String())
+		}
+		if used != test.WantUsed {
+			t.Errorf("%s: middleware used: want %q; have %q", test.URL, test.WantUsed, used)
+		}
+	}
+
+	routes := r.Patterns()
+	if len(routes) != 1 {
+		t.Errorf("host routes must not be in patterns: %q", routes)
+	}
+
+	var have []string
+	for _, route := range r.Routes() {
+		have = append(have, route.Host)
+	}
+	if want := fmt.Sprint([]string{"", "api.example.com", "{tenant}.example.com"}); fmt.Sprint(have) != want {
+		t.Errorf("route hosts want %s; have %q", want, have)
+	}
+}
+
+func TestRouterHostPrefix(t *testing.T) {
+	hf := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Pattern)
+	}
+
+	r := NewRouter()
+	r.HandleFunc("GET /items", hf)
+	r.Route("/api", func(r *Router) {
+		r.HandleFunc("GET /status", hf)
+		r.Host("a.example.com", func(r *Router) {
+			r.HandleFunc("GET /items", hf)
+			r.HandleFunc("POST /orders", hf)
+		})
+	})
+
+	tests := []struct {
+		Method     string
+		URL        string
+		WantStatus int
+		WantBody   string
+	}{
+		{"GET", "http://a.example.com/api/items", http.StatusOK, "GET a.example.com/api/items"},
+		{"GET", "http://a.example.com/items", http.StatusOK, "GET /items"},
+		{"GET", "http://a.example.com/api/status", http.StatusOK, "GET /api/status"},
+		{"GET", "http://b.example.com/api/items", http.StatusNotFound, ""},
+		{"GET", "http://a.example.com/api/orders", http.StatusMethodNotAllowed, ""},
+		{"OPTIONS", "http://a.example.com/api/orders", http.StatusNoContent, ""},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(test.Method, test.URL, nil))
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s %s: status want %d; have %d", test.Method, test.URL, test.WantStatus, rr.Code)
+		}
+		if test.WantBody != "" && rr.Body.String() != test.WantBody {
+			t.Errorf("%s %s: body want %q; have %q", test.Method, test.URL, test.WantBody, rr.Body.String())
+		}
+	}
+
+	var have []string
+	for _, route := range r.Routes() {
+		have = append(have, route.Pattern)
+	}
+	want := []string{"GET /items", "GET /api/status", "GET a.example.com/api/items", "POST a.example.com/api/orders"}
+	if fmt.Sprint(have) != fmt.Sprint(want) {
+		t.Errorf("routes want %q; have %q", want, have)
+	}
+}
@@ -2,6 +2,7 @@ package httpx
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httputil"
@@ -12,6 +13,8 @@ type Error struct {
 	Code    int    `json:"code,omitempty"`
 	Type    string `json:"type,omitempty"`
 	Message string `json:"msg,omitempty"`
+	// Fields with invalid values, see [Bind] and [Validate].
+	Fields []FieldError `json:"fields,omitempty"`
 }
 
 func (e Error) Error() string {
@@ -19,7 +22,7 @@ func (e Error) Error() string {
 }
 
 // ErrorResponse return and error wrapped into JSON.
-// If the err is httpx.Error, code parameter is ignoted and httpx.Error.Code is used.
+// If the err is or wraps httpx.Error, code parameter is ignoted and httpx.Error.Code is used.
 // If the err is nil just status code is returned.
 func ErrorResponse(w http.ResponseWriter, code int, err error) {
 	if err == nil {
@@ -27,15 +30,17 @@ func ErrorResponse(w http.ResponseWriter, code int, err error) {
 		return
 	}
 
-	var resp *Error
-	if e, ok := err.(*Error); ok {
-		resp = e
-		code = resp.Code
-	} else {
+	resp, ok := asError(err)
+	switch {
+	case !ok:
 		resp = &Error{
 			Code:    code,
 			Message: err.Error(),
 		}
+	case resp.Code == 0:
+		resp.Code = code
+	default:
+		code = resp.Code
 	}
 
 	// TODO: indent under compile flag?
@@ -46,6 +51,25 @@ func ErrorResponse(w http.ResponseWriter, code int, err error) {
 	w.Write(raw)
 }
 
+// asError returns a copy of *Error or Error from the err chain.
+// [Problem] is converted to [Error].
+func asError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) && e != nil {
+		copied := *e
+		return &copied, true
+	}
+	var ev Error
+	if errors.As(err, &ev) {
+		return &ev, true
+	}
+	var p *Problem
+	if errors.As(err, &p) && p != nil {
+		return &Error{Code: p.Status, Type: p.Type, Message: p.Error()}, true
+	}
+	return nil, false
+}
+
 func DumpRequest(r *http.Request) string {
 	raw, err := httputil.DumpRequest(r, true)
 	if err != nil {
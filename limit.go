@@ -0,0 +1,266 @@
+package httpx
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConnLimitStats is a snapshot of connection limiter counters.
+type ConnLimitStats struct {
+	Active        int64  // Connections holding a slot.
+	Rejected      uint64 // Connections rejected due to MaxConns.
+	RejectedPerIP uint64 // Connections rejected due to MaxConnsPerIP.
+}
+
+// ConnLimitMode is how connections over [ServerConfig.MaxConns] are handled.
+type ConnLimitMode string
+
+const (
+	// ConnLimitQueue waits in Accept for a free slot up to [ServerConfig.ConnQueueTimeout],
+	// connections still over the limit are rejected.
+	ConnLimitQueue ConnLimitMode = "queue"
+	// ConnLimitReject accepts and rejects connections over the limit immediately.
+	ConnLimitReject ConnLimitMode = "reject"
+)
+
+var (
+	errConnLimit      = errors.New("httpx: too many connections")
+	errConnLimitPerIP = errors.New("httpx: too many connections from a single IP")
+)
+
+// response written to rejected plain HTTP connections.
+const connLimitResponse = "HTTP/1.1 503 Service Unavailable\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Length: 20\r\n" +
+	"Connection: close\r\n" +
+	"\r\n" +
+	"Service Unavailable\n"
+
+type connLimiter struct {
+	sem   chan struct{} // nil if there is no global limit.
+	perIP int
+	queue time.Duration
+	plain bool // write 503 on reject, TLS connections are just closed.
+
+	mu  sync.Mutex
+	ips map[netip.Addr]int
+
+	active        atomic.Int64
+	rejected      atomic.Uint64
+	rejectedPerIP atomic.Uint64
+}
+
+func newConnLimiter(cfg *ServerConfig) *connLimiter {
+	l := &connLimiter{
+		perIP: cfg.MaxConnsPerIP,
+		plain: !cfg.tlsEnabled(),
+		ips:   map[netip.Addr]int{},
+	}
+	if cfg.ConnLimitMode == ConnLimitQueue {
+		l.queue = cfg.ConnQueueTimeout
+	}
+	if cfg.MaxConns > 0 {
+		l.sem = make(chan struct{}, cfg.MaxConns)
+	}
+	return l
+}
+
+func (l *connLimiter) stats() ConnLimitStats {
+	return ConnLimitStats{
+		Active:        l.active.Load(),
+		Rejected:      l.rejected.Load(),
+		RejectedPerIP: l.rejectedPerIP.Load(),
+	}
+}
+
+// acquireSlot waits for a free slot up to the queue timeout.
+func (l *connLimiter) acquireSlot() error {
+	if l.sem != nil && !l.waitSlot() {
+		l.rejected.Add(1)
+		return errConnLimit
+	}
+	l.active.Add(1)
+	return nil
+}
+
+func (l *connLimiter) waitSlot() bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.queue <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(l.queue)
+	defer timer.Stop()
+
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (l *connLimiter) releaseSlot() {
+	l.active.Add(-1)
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+func (l *connLimiter) acquireIP(ip netip.Addr) error {
+	if l.perIP <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ips[ip] >= l.perIP {
+		l.rejectedPerIP.Add(1)
+		return errConnLimitPerIP
+	}
+	l.ips[ip]++
+	return nil
+}
+
+func (l *connLimiter) releaseIP(ip netip.Addr) {
+	if l.perIP <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ips[ip]--; l.ips[ip] <= 0 {
+		delete(l.ips, ip)
+	}
+}
+
+// reject answers plain HTTP connections with 503, TLS connections are just closed.
+func (l *connLimiter) reject(conn net.Conn) {
+	if l.plain {
+		conn.SetWriteDeadline(time.Now().Add(time.Second))
+		conn.Write([]byte(connLimitResponse))
+	}
+	conn.Close()
+}
+
+// limitListener enforces connection limits in Accept.
+// In queue mode Accept waits for a free slot, so connections
+// over MaxConns are left in the kernel backlog instead of being served.
+type limitListener struct {
+	net.Listener
+	limiter *connLimiter
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := l.limiter.acquireSlot(); err != nil {
+			// client might not read the response, don't block the accept loop.
+			go l.limiter.reject(conn)
+			continue
+		}
+		c := &limitConn{Conn: conn, limiter: l.limiter}
+
+		// with PROXY protocol the client IP is known once the header is read,
+		// it's done on the first read to not block the accept loop.
+		if _, ok := conn.(*proxyConn); ok {
+			return c, nil
+		}
+		ok := false
+		c.ipOnce.Do(func() {
+			ok = c.acquirePerIP(conn.RemoteAddr())
+		})
+		if ok {
+			return c, nil
+		}
+		c.release()
+		go l.limiter.reject(conn)
+	}
+}
+
+// limitConn holds a slot of [connLimiter] until closed.
+type limitConn struct {
+	net.Conn
+	limiter *connLimiter
+
+	ipOnce   sync.Once
+	ip       netip.Addr
+	ipHeld   atomic.Bool
+	err      error
+	closed   atomic.Bool
+	slotDone sync.Once
+	ipDone   sync.Once
+}
+
+func (c *limitConn) Read(b []byte) (int, error) {
+	if err := c.acquireIP(); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *limitConn) Write(b []byte) (int, error) {
+	if err := c.acquireIP(); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
+
+func (c *limitConn) Close() error {
+	c.closed.Store(true)
+	c.release()
+	return c.Conn.Close()
+}
+
+func (c *limitConn) release() {
+	c.slotDone.Do(c.limiter.releaseSlot)
+	if c.ipHeld.Load() {
+		c.ipDone.Do(func() {
+			c.limiter.releaseIP(c.ip)
+		})
+	}
+}
+
+// acquireIP checks the per IP limit once, the connection is rejected if it's exceeded.
+func (c *limitConn) acquireIP() error {
+	c.ipOnce.Do(func() {
+		if !c.acquirePerIP(c.Conn.RemoteAddr()) {
+			c.release()
+			c.limiter.reject(c.Conn)
+		}
+	})
+	return c.err
+}
+
+func (c *limitConn) acquirePerIP(addr net.Addr) bool {
+	if ap, err := netip.ParseAddrPort(addr.String()); err == nil {
+		c.ip = ap.Addr().Unmap()
+	}
+
+	c.err = c.limiter.acquireIP(c.ip)
+	if c.err != nil {
+		return false
+	}
+
+	c.ipHeld.Store(true)
+	// connection might be closed while reading the PROXY header.
+	if c.closed.Load() {
+		c.release()
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+package httpx
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitListener(t *testing.T) {
+	tests := []struct {
+		Name string
+		Cfg  ServerConfig
+		Want ConnLimitStats
+	}{
+		{"max conns", ServerConfig{MaxConns: 1}, ConnLimitStats{Active: 1, Rejected: 1}},
+		{"max conns per ip", ServerConfig{MaxConnsPerIP: 1}, ConnLimitStats{Active: 1, RejectedPerIP: 1}},
+		{
+			"queue timeout",
+			ServerConfig{MaxConns: 1, ConnLimitMode: ConnLimitQueue, ConnQueueTimeout: 20 * time.Millisecond},
+			ConnLimitStats{Active: 1, Rejected: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			l := newConnLimiter(&test.Cfg)
+			ln := newTestLimitListener(t, l, nil)
+
+			dialTest(t, ln.Addr())
+			first, err := ln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Accept blocks until the next accepted connection, rejected one is skipped.
+			accepted := make(chan net.Conn, 1)
+			go func() {
+				conn, err := ln.Accept()
+				if err == nil {
+					accepted <- conn
+				}
+			}()
+
+			second := dialTest(t, ln.Addr())
+			resp, err := io.ReadAll(second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(resp), "HTTP/1.1 503 ") {
+				t.Errorf("unexpected response: %q", resp)
+			}
+			if have := l.stats(); have != test.Want {
+				t.Errorf("\nhave: %+v\nwant: %+v", have, test.Want)
+			}
+
+			// closed connection frees its slot.
+			first.Close()
+			first.Close()
+			dialTest(t, ln.Addr())
+			select {
+			case conn := <-accepted:
+				conn.Close()
+			case <-time.After(5 * time.Second):
+				t.Fatal("connection is not accepted after a slot is freed")
+			}
+			if have := l.stats(); have.Active != 0 {
+				t.Errorf("active want 0; have %d", have.Active)
+			}
+		})
+	}
+}
+
+func TestLimitListenerQueue(t *testing.T) {
+	l := newConnLimiter(&ServerConfig{MaxConns: 1, ConnLimitMode: ConnLimitQueue, ConnQueueTimeout: 5 * time.Second})
+	ln := newTestLimitListener(t, l, nil)
+
+	dialTest(t, ln.Addr())
+	first, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialTest(t, ln.Addr())
+	time.AfterFunc(10*time.Millisecond, func() { first.Close() })
+
+	second, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	if have, want := l.stats(), (ConnLimitStats{Active: 1}); have != want {
+		t.Errorf("\nhave: %+v\nwant: %+v", have, want)
+	}
+}
+
+func TestLimitListenerProxyPerIP(t *testing.T) {
+	l := newConnLimiter(&ServerConfig{MaxConnsPerIP: 1})
+	ln := newTestLimitListener(t, l, &ProxyProtocolConfig{TrustAll: true})
+
+	const header = "PROXY TCP4 192.168.0.1 10.0.0.1 56324 443\r\n"
+
+	dialTest(t, ln.Addr()).Write([]byte(header + "ping"))
+	first, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(first, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("have %q, %v", buf, err)
+	}
+
+	client := dialTest(t, ln.Addr())
+	client.Write([]byte(header))
+	second, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	if _, err := second.Read(buf); err != errConnLimitPerIP {
+		t.Errorf("have %v, want %v", err, errConnLimitPerIP)
+	}
+	resp, err := io.ReadAll(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(resp), "HTTP/1.1 503 ") {
+		t.Errorf("unexpected response: %q", resp)
+	}
+	if have, want := l.stats(), (ConnLimitStats{Active: 1, RejectedPerIP: 1}); have != want {
+		t.Errorf("\nhave: %+v\nwant: %+v", have, want)
+	}
+}
+
+// newTestLimitListener wraps a TCP listener like [Server] does.
+func newTestLimitListener(t *testing.T, l *connLimiter, proxy *ProxyProtocolConfig) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	if proxy != nil {
+		ln = newProxyListener(ln, proxy)
+	}
+	return &limitListener{Listener: ln, limiter: l}
+}
+
+func dialTest(t *testing.T, addr net.Addr) net.Conn {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
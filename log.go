@@ -0,0 +1,52 @@
+package httpx
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// serverLogWriter converts [http.Server.ErrorLog] messages into structured logs.
+type serverLogWriter struct {
+	logger *slog.Logger
+}
+
+func (w *serverLogWriter) Write(p []byte) (int, error) {
+	level, msg, attrs := parseServerLog(strings.TrimSuffix(string(p), "\n"))
+	w.logger.LogAttrs(context.Background(), level, msg, attrs...)
+	return len(p), nil
+}
+
+// parseServerLog extracts level and attributes from well-known net/http messages.
+func parseServerLog(line string) (slog.Level, string, []slog.Attr) {
+	if rest, ok := strings.CutPrefix(line, "http: panic serving "); ok {
+		addr, rest, _ := strings.Cut(rest, ": ")
+		value, stack, _ := strings.Cut(rest, "\n")
+		return slog.LevelError, "panic serving request", []slog.Attr{
+			slog.String("remote_addr", addr),
+			slog.String("panic", value),
+			slog.String("stack", stack),
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(line, "http: TLS handshake error from "); ok {
+		addr, errMsg, _ := strings.Cut(rest, ": ")
+		return slog.LevelDebug, "TLS handshake error", []slog.Attr{
+			slog.String("remote_addr", addr),
+			slog.String("error", errMsg),
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(line, "http: Accept error: "); ok {
+		errMsg, retry, _ := strings.Cut(rest, "; retrying in ")
+		return slog.LevelWarn, "accept error", []slog.Attr{
+			slog.String("error", errMsg),
+			slog.String("retry_in", retry),
+		}
+	}
+
+	if strings.HasPrefix(line, "http: superfluous response.WriteHeader call") {
+		return slog.LevelWarn, line, nil
+	}
+	return slog.LevelError, line, nil
+}
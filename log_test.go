@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestServerLogWriter(t *testing.T) {
+	testCases := []struct {
+		line string
+		want []string
+	}{
+		{
+			line: "http: panic serving 10.0.0.1:1234: boom\ngoroutine 1 [running]:\n",
+			want: []string{"level=ERROR", `msg="panic serving request"`, "remote_addr=10.0.0.1:1234", "panic=boom", `stack="goroutine 1 [running]:"`},
+		},
+		{
+			line: "http: TLS handshake error from 10.0.0.1:1234: EOF\n",
+			want: []string{"level=DEBUG", `msg="TLS handshake error"`, "remote_addr=10.0.0.1:1234", "error=EOF"},
+		},
+		{
+			line: "http: Accept error: too many open files; retrying in 5ms\n",
+			want: []string{"level=WARN", `msg="accept error"`, `error="too many open files"`, "retry_in=5ms"},
+		},
+		{
+			line: "http2: something unexpected\n",
+			want: []string{"level=ERROR", `msg="http2: something unexpected"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		l := log.New(&serverLogWriter{logger: logger}, "", 0)
+		l.Print(tc.line)
+
+		for _, want := range tc.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("log %q must contain %q", buf.String(), want)
+			}
+		}
+	}
+}
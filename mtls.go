@@ -0,0 +1,165 @@
+package httpx
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+)
+
+// ClientIdentity of a client authenticated with a TLS certificate.
+type ClientIdentity struct {
+	CommonName string
+	DNSNames   []string
+	URIs       []*url.URL
+	// SPIFFEID is the first spiffe:// URI from the certificate, if any.
+	SPIFFEID    string
+	Certificate *x509.Certificate
+}
+
+func newClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	id := &ClientIdentity{
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		URIs:        cert.URIs,
+		Certificate: cert,
+	}
+	for _, u := range cert.URIs {
+		if u.Scheme == "spiffe" {
+			id.SPIFFEID = u.String()
+			break
+		}
+	}
+	return id
+}
+
+type clientIdentityKey struct{}
+
+// ClientIdentityFromContext returns identity set by [ClientCertMiddleware].
+// Returns nil if the request has no verified client certificate.
+func ClientIdentityFromContext(ctx context.Context) *ClientIdentity {
+	id, _ := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+	return id
+}
+
+// ClientCertMiddleware verifies client certificate against roots
+// and puts [ClientIdentity] into the request context.
+// If roots is nil, chains verified during TLS handshake are required,
+// see [tls.Config.ClientAuth].
+// Requests without a valid certificate get 401 Unauthorized.
+func ClientCertMiddleware(roots *x509.CertPool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cert, err := verifyClientCert(r, roots)
+			if err != nil {
+				ErrorResponse(w, http.StatusUnauthorized, &Error{
+					Code:    http.StatusUnauthorized,
+					Message: err.Error(),
+				})
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), clientIdentityKey{}, newClientIdentity(cert))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func verifyClientCert(r *http.Request, roots *x509.CertPool) (*x509.Certificate, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, errors.New("client certificate is required")
+	}
+
+	if roots == nil {
+		if len(r.TLS.VerifiedChains) == 0 {
+			return nil, errors.New("client certificate is not verified")
+		}
+		return r.TLS.VerifiedChains[0][0], nil
+	}
+
+	certs := r.TLS.PeerCertificates
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, errors.New("client certificate is not valid")
+	}
+	return certs[0], nil
+}
+
+// ClientRule reports whether a client is allowed.
+type ClientRule func(id *ClientIdentity) bool
+
+// AllowClients allows requests only from clients matching any of the rules.
+// Must be used after [ClientCertMiddleware], usually in [Router.Group].
+// Requests without identity get 401 Unauthorized, not allowed clients get 403 Forbidden.
+func AllowClients(rules ...ClientRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := ClientIdentityFromContext(r.Context())
+			if id == nil {
+				ErrorResponse(w, http.StatusUnauthorized, &Error{
+					Code:    http.StatusUnauthorized,
+					Message: "client certificate is required",
+				})
+				return
+			}
+
+			for _, rule := range rules {
+				if rule(id) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			ErrorResponse(w, http.StatusForbidden, &Error{
+				Code:    http.StatusForbidden,
+				Message: "client is not allowed",
+			})
+		})
+	}
+}
+
+// AllowCommonName rule matches certificate subject common name.
+func AllowCommonName(names ...string) ClientRule {
+	return func(id *ClientIdentity) bool {
+		return slices.Contains(names, id.CommonName)
+	}
+}
+
+// AllowDNSName rule matches any of the certificate DNS names.
+func AllowDNSName(names ...string) ClientRule {
+	return func(id *ClientIdentity) bool {
+		for _, name := range id.DNSNames {
+			if slices.Contains(names, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllowSPIFFEID rule matches SPIFFE ID with patterns in [path.Match] syntax,
+// like spiffe://example.org/ns/*/sa/api.
+func AllowSPIFFEID(patterns ...string) ClientRule {
+	return func(id *ClientIdentity) bool {
+		if id.SPIFFEID == "" {
+			return false
+		}
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, id.SPIFFEID); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
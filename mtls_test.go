@@ -0,0 +1,85 @@
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCert(t *testing.T) {
+	ca, err := NewDevCA(KeyECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewDevCA(KeyECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(ca *DevCA, hosts ...string) []*x509.Certificate {
+		cert, err := ca.Issue(hosts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []*x509.Certificate{cert.Leaf}
+	}
+	api := issue(ca, "api", "spiffe://example.org/ns/prod/sa/api")
+	web := issue(ca, "web", "web.example.org")
+	foreign := issue(other, "api", "spiffe://example.org/ns/prod/sa/api")
+
+	var have *ClientIdentity
+	hf := func(w http.ResponseWriter, r *http.Request) {
+		have = ClientIdentityFromContext(r.Context())
+	}
+
+	r := NewRouter()
+	r.Use(ClientCertMiddleware(ca.CertPool()))
+	r.HandleFunc("GET /public", hf)
+	r.Group(func(r *Router) {
+		r.Use(AllowClients(AllowSPIFFEID("spiffe://example.org/ns/*/sa/api"), AllowDNSName("admin.example.org")))
+		r.HandleFunc("GET /admin", hf)
+	})
+	r.Group(func(r *Router) {
+		r.Use(AllowClients(AllowCommonName("web")))
+		r.HandleFunc("GET /web", hf)
+	})
+
+	tests := []struct {
+		Path       string
+		Certs      []*x509.Certificate
+		WantStatus int
+		WantName   string
+	}{
+		{"/public", nil, http.StatusUnauthorized, ""},
+		{"/public", foreign, http.StatusUnauthorized, ""},
+		{"/public", web, http.StatusOK, "web"},
+		{"/admin", web, http.StatusForbidden, ""},
+		{"/web", web, http.StatusOK, "web"},
+		{"/web", api, http.StatusForbidden, ""},
+		{"/admin", api, http.StatusOK, "api"},
+	}
+
+	for _, test := range tests {
+		have = nil
+
+		rq := httptest.NewRequest(http.MethodGet, test.Path, nil)
+		if test.Certs != nil {
+			rq.TLS = &tls.ConnectionState{PeerCertificates: test.Certs}
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, rq)
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s: status want %d; have %d", test.Path, test.WantStatus, rr.Code)
+		}
+		if test.WantName != "" && (have == nil || have.CommonName != test.WantName) {
+			t.Errorf("%s: identity want %q; have %+v", test.Path, test.WantName, have)
+		}
+	}
+
+	if have.SPIFFEID != "spiffe://example.org/ns/prod/sa/api" {
+		t.Errorf("spiffe id: have %q", have.SPIFFEID)
+	}
+}
@@ -0,0 +1,382 @@
+package httpx
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIInfo is the info object of OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIHandler serves OpenAPI 3.1 document of the router as JSON.
+// Document is generated on each request, so routes registered later are included.
+//
+//	r.Handle("GET /openapi.json", r.OpenAPIHandler(httpx.OpenAPIInfo{Title: "API", Version: "1.0"}))
+func (r *Router) OpenAPIHandler(info OpenAPIInfo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		raw, err := r.OpenAPI(info)
+		if err != nil {
+			ErrorResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(raw)
+	})
+}
+
+// OpenAPI returns OpenAPI 3.1 JSON document for the routes with a method.
+// Schemas are generated from [RouteMeta] types with reflection.
+// Named struct types are placed into components/schemas, fields bound by [Bind] tags
+// are not part of the body schema.
+// Routes with the same path and method, like routes of different hosts or versions,
+// are reported as an error.
+func (r *Router) OpenAPI(info OpenAPIInfo) ([]byte, error) {
+	doc := &openAPIDocument{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   map[string]map[string]*openAPIOperation{},
+	}
+	schemas := &schemaBuilder{
+		schemas: map[string]*jsonSchema{},
+		names:   map[reflect.Type]string{},
+		types:   map[string]reflect.Type{},
+	}
+
+	// operations are keyed by path and method, routes of hosts and versions can collide.
+	seen := map[string]RouteInfo{}
+	for _, route := range r.Routes() {
+		if route.Method == "" {
+			continue
+		}
+
+		path := strings.TrimSuffix(route.Path, "{$}")
+		path = strings.ReplaceAll(path, "...}", "}")
+		method := strings.ToLower(route.Method)
+
+		key := method + " " + path
+		if prev, ok := seen[key]; ok {
+			return nil, fmt.Errorf("httpx: routes %s and %s have the same OpenAPI operation %s %s",
+				openAPIRouteName(prev), openAPIRouteName(route), route.Method, path)
+		}
+		seen[key] = route
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*openAPIOperation{}
+		}
+		op, err := newOpenAPIOperation(route, schemas, doc)
+		if err != nil {
+			return nil, err
+		}
+		doc.Paths[path][method] = op
+	}
+
+	if len(schemas.schemas) > 0 {
+		doc.Components.Schemas = schemas.schemas
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func openAPIRouteName(route RouteInfo) string {
+	if route.Version != "" {
+		return strconv.Quote(route.Pattern) + " of version " + route.Version
+	}
+	return strconv.Quote(route.Pattern)
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas         map[string]*jsonSchema      `json:"schemas,omitempty"`
+		SecuritySchemes map[string]*openAPISecurity `json:"securitySchemes,omitempty"`
+	} `json:"components,omitzero"`
+}
+
+type openAPIOperation struct {
+	Summary     string                  `json:"summary,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Parameters  []*openAPIParameter     `json:"parameters,omitempty"`
+	RequestBody *openAPIBody            `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIBody `json:"responses"`
+	Security    []map[string][]string   `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      *jsonSchema `json:"schema"`
+}
+
+// openAPIBody is a request body or a response object.
+type openAPIBody struct {
+	Description string                      `json:"description,omitempty"`
+	Required    bool                        `json:"required,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *jsonSchema `json:"schema"`
+}
+
+type openAPISecurity struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+func newOpenAPIOperation(route RouteInfo, schemas *schemaBuilder, doc *openAPIDocument) (*openAPIOperation, error) {
+	meta := route.Meta
+	op := &openAPIOperation{
+		Summary:   meta.Summary,
+		Tags:      meta.Tags,
+		Responses: map[string]*openAPIBody{},
+	}
+
+	params := slices.Clone(meta.Params)
+	for _, name := range route.Wildcards {
+		if !slices.ContainsFunc(params, func(p Param) bool { return p.In == "path" && p.Name == name }) {
+			params = append(params, Param{Name: name, In: "path"})
+		}
+	}
+	for _, p := range params {
+		typ := p.Type
+		if typ == nil {
+			typ = reflect.TypeFor[string]()
+		}
+		op.Parameters = append(op.Parameters, &openAPIParameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required || p.In == "path",
+			Schema:      schemas.schema(typ),
+		})
+	}
+
+	if meta.Request != nil {
+		op.RequestBody = &openAPIBody{
+			Required: true,
+			Content:  jsonContent(schemas.schema(meta.Request)),
+		}
+	}
+
+	for code, typ := range meta.Responses {
+		resp := &openAPIBody{Description: http.StatusText(code)}
+		if typ != nil {
+			resp.Content = jsonContent(schemas.schema(typ))
+		}
+		op.Responses[strconv.Itoa(code)] = resp
+	}
+	for _, code := range meta.Errors {
+		op.Responses[strconv.Itoa(code)] = &openAPIBody{
+			Description: http.StatusText(code),
+			Content:     jsonContent(schemas.schema(reflect.TypeFor[Error]())),
+		}
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = &openAPIBody{Description: http.StatusText(http.StatusOK)}
+	}
+
+	if meta.Auth != "" {
+		security, ok := openAPISecuritySchemes[meta.Auth]
+		if !ok {
+			return nil, fmt.Errorf("httpx: unsupported auth %q of route %q", meta.Auth, route.Pattern)
+		}
+		op.Security = []map[string][]string{{meta.Auth: {}}}
+
+		if doc.Components.SecuritySchemes == nil {
+			doc.Components.SecuritySchemes = map[string]*openAPISecurity{}
+		}
+		doc.Components.SecuritySchemes[meta.Auth] = security
+	}
+	return op, nil
+}
+
+// openAPISecuritySchemes by [RouteMeta.Auth] values.
+var openAPISecuritySchemes = map[string]*openAPISecurity{
+	"mtls":   {Type: "mutualTLS"},
+	"basic":  {Type: "http", Scheme: "basic"},
+	"bearer": {Type: "http", Scheme: "bearer"},
+	"digest": {Type: "http", Scheme: "digest"},
+}
+
+func jsonContent(schema *jsonSchema) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{"application/json": {Schema: schema}}
+}
+
+// jsonSchema is a subset of JSON Schema used by OpenAPI 3.1.
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+type schemaBuilder struct {
+	schemas map[string]*jsonSchema
+	names   map[reflect.Type]string
+	types   map[string]reflect.Type
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) *jsonSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &jsonSchema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &jsonSchema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &jsonSchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: "integer", Format: intFormat(t)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &jsonSchema{Type: "integer", Format: intFormat(t), Minimum: &zero}
+	case reflect.Float32:
+		return &jsonSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &jsonSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &jsonSchema{Type: "string", Format: "byte"}
+		}
+		return &jsonSchema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := b.componentName(t)
+		if _, ok := b.schemas[name]; !ok {
+			b.schemas[name] = &jsonSchema{} // placeholder for recursive types.
+			b.schemas[name] = b.structSchema(t)
+		}
+		return &jsonSchema{Ref: "#/components/schemas/" + name}
+	default:
+		return &jsonSchema{}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) *jsonSchema {
+	s := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		// bound fields are parameters or form values, see [Bind].
+		if in, _ := fieldBinding(f); in != "" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := b.structSchema(ft)
+				for k, v := range embedded.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name = cmp.Or(name, f.Name)
+		options := strings.Split(opts, ",")
+
+		prop := b.schema(f.Type)
+		if slices.Contains(options, "string") {
+			prop = &jsonSchema{Type: "string"}
+		}
+		s.Properties[name] = prop
+
+		optional := slices.Contains(options, "omitempty") || slices.Contains(options, "omitzero")
+		if !optional && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+func intFormat(t reflect.Type) string {
+	if t.Bits() == 64 {
+		return "int64"
+	}
+	return "int32"
+}
+
+// componentName returns a unique name of a named type in components.
+// Types with the same name from different packages are qualified by the package.
+func (b *schemaBuilder) componentName(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	qualified := schemaName(t.PkgPath() + "." + t.Name())
+	candidates := []string{
+		schemaName(t.Name()),
+		schemaName(path.Base(t.PkgPath()) + "." + t.Name()),
+		qualified,
+	}
+	// local types of different functions have the same package and name.
+	for i := 2; ; i++ {
+		for _, name := range candidates {
+			if _, ok := b.types[name]; !ok {
+				b.names[t], b.types[name] = name, t
+				return name
+			}
+		}
+		candidates = []string{qualified + "_" + strconv.Itoa(i)}
+	}
+}
+
+// schemaName of a type name, type arguments are kept as a suffix.
+func schemaName(name string) string {
+	return strings.NewReplacer("[", "_", "]", "", ",", "_", "*", "", "/", "_").Replace(name)
+}
@@ -0,0 +1,156 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRouterOpenAPI(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		ID        int64             `json:"id"`
+		Name      string            `json:"name"`
+		Email     string            `json:"email,omitempty"`
+		CreatedAt time.Time         `json:"created_at"`
+		Address   *Address          `json:"address"`
+		Friends   []User            `json:"friends,omitempty"`
+		Labels    map[string]string `json:"labels,omitempty"`
+		Token     string            `header:"X-Token"`
+		secret    string
+	}
+
+	r := NewRouter()
+	r.WithMeta(RouteMeta{
+		Summary:   "Get user",
+		Tags:      []string{"users"},
+		Auth:      "bearer",
+		Responses: map[int]reflect.Type{http.StatusOK: reflect.TypeFor[User]()},
+		Errors:    []int{http.StatusNotFound},
+		Params:    []Param{{Name: "fields", In: "query", Type: reflect.TypeFor[[]string]()}},
+	}).HandleFunc("GET /users/{id}", NoopHandler)
+	r.WithMeta(RouteMeta{
+		Request:   reflect.TypeFor[User](),
+		Responses: map[int]reflect.Type{http.StatusCreated: reflect.TypeFor[User](), http.StatusNoContent: nil},
+	}).HandleFunc("POST /users/{$}", NoopHandler)
+	r.Handle("/static/", http.NotFoundHandler())
+	r.Handle("GET /openapi.json", r.OpenAPIHandler(OpenAPIInfo{Title: "Test", Version: "1.0"}))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	var doc struct {
+		OpenAPI    string
+		Paths      map[string]map[string]json.RawMessage
+		Components struct {
+			Schemas         map[string]json.RawMessage
+			SecuritySchemes map[string]json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi: have %q", doc.OpenAPI)
+	}
+	if _, ok := doc.Paths["/static/"]; ok {
+		t.Error("route without method must be skipped")
+	}
+
+	wantJSON := map[string]string{
+		"get /users/{id}": `{"summary":"Get user","tags":["users"],"parameters":[` +
+			`{"name":"fields","in":"query","schema":{"type":"array","items":{"type":"string"}}},` +
+			`{"name":"id","in":"path","required":true,"schema":{"type":"string"}}],` +
+			`"responses":{"200":{"description":"OK","content":{"application/json":{"schema":{"$ref":"#/components/schemas/User"}}}},` +
+			`"404":{"description":"Not Found","content":{"application/json":{"schema":{"$ref":"#/components/schemas/Error"}}}}},` +
+			`"security":[{"bearer":[]}]}`,
+		"post /users/": `{"requestBody":{"required":true,"content":{"application/json":{"schema":{"$ref":"#/components/schemas/User"}}}},` +
+			`"responses":{"201":{"description":"Created","content":{"application/json":{"schema":{"$ref":"#/components/schemas/User"}}}},` +
+			`"204":{"description":"No Content"}}}`,
+		"schema User": `{"type":"object","properties":{` +
+			`"address":{"$ref":"#/components/schemas/Address"},` +
+			`"created_at":{"type":"string","format":"date-time"},` +
+			`"email":{"type":"string"},` +
+			`"friends":{"type":"array","items":{"$ref":"#/components/schemas/User"}},` +
+			`"id":{"type":"integer","format":"int64"},` +
+			`"labels":{"type":"object","additionalProperties":{"type":"string"}},` +
+			`"name":{"type":"string"}},` +
+			`"required":["id","name","created_at"]}`,
+		"schema Error": `{"type":"object","properties":{"code":{"type":"integer","format":"int64"},"msg":{"type":"string"},"type":{"type":"string"},` +
+			`"fields":{"type":"array","items":{"$ref":"#/components/schemas/FieldError"}}}}`,
+		"security bearer": `{"type":"http","scheme":"bearer"}`,
+	}
+
+	have := map[string]json.RawMessage{
+		"get /users/{id}": doc.Paths["/users/{id}"]["get"],
+		"post /users/":    doc.Paths["/users/"]["post"],
+		"schema User":     doc.Components.Schemas["User"],
+		"schema Error":    doc.Components.Schemas["Error"],
+		"security bearer": doc.Components.SecuritySchemes["bearer"],
+	}
+
+	for name, want := range wantJSON {
+		var w, h any
+		json.Unmarshal([]byte(want), &w)
+		json.Unmarshal(have[name], &h)
+		if !reflect.DeepEqual(w, h) {
+			t.Errorf("%s:\nwant %s\nhave %s", name, want, have[name])
+		}
+	}
+}
+
+func TestRouterOpenAPIErrors(t *testing.T) {
+	// same name as httpx.Error which is used for error responses.
+	type Error struct {
+		Reason string `json:"reason"`
+	}
+
+	r := NewRouter()
+	r.WithMeta(RouteMeta{
+		Auth:      "mtls",
+		Responses: map[int]reflect.Type{http.StatusOK: reflect.TypeFor[Error]()},
+		Errors:    []int{http.StatusNotFound},
+	}).HandleFunc("GET /reasons/{id}", NoopHandler)
+
+	raw, err := r.OpenAPI(OpenAPIInfo{Title: "Test", Version: "1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Components struct {
+			Schemas         map[string]json.RawMessage
+			SecuritySchemes map[string]struct{ Type string }
+		}
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Components.Schemas) != 3 { // 2 errors and FieldError.
+		t.Errorf("want distinct schemas; have %s", raw)
+	}
+	if have := doc.Components.SecuritySchemes["mtls"].Type; have != "mutualTLS" {
+		t.Errorf("security scheme: have %q", have)
+	}
+
+	r.WithMeta(RouteMeta{Auth: "apiKey"}).HandleFunc("GET /keys", NoopHandler)
+	if _, err := r.OpenAPI(OpenAPIInfo{}); err == nil {
+		t.Error("want error for unsupported auth")
+	}
+
+	r = NewRouter()
+	r.HandleFunc("GET /items", NoopHandler)
+	r.Version(Version{Name: "2"}, func(r *Router) {
+		r.HandleFunc("GET /items", NoopHandler)
+	})
+	_, err = r.OpenAPI(OpenAPIInfo{})
+	if want := `httpx: routes "GET /items" and "GET /items" of version 2 have the same OpenAPI operation GET /items`; err == nil || err.Error() != want {
+		t.Errorf("want error %q; have %v", want, err)
+	}
+}
@@ -0,0 +1,233 @@
+package httpx
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is a media type of [Problem].
+const ProblemContentType = "application/problem+json"
+
+// Problem details for HTTP APIs, see RFC 9457.
+type Problem struct {
+	// Type is a URI of the problem type, "about:blank" if empty.
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions are additional members of the problem object.
+	Extensions map[string]any
+}
+
+func (p *Problem) Error() string {
+	return cmp.Or(p.Detail, p.Title, http.StatusText(p.Status))
+}
+
+// MarshalJSON implements [json.Marshaler], extensions are top-level members.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+
+	members := []struct {
+		name  string
+		value string
+	}{
+		{"type", p.Type},
+		{"title", p.Title},
+		{"detail", p.Detail},
+		{"instance", p.Instance},
+	}
+	for _, member := range members {
+		if member.value != "" {
+			m[member.name] = member.value
+		}
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements [json.Unmarshaler], unknown members are put into extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	*p = Problem{}
+	for k, raw := range m {
+		var err error
+		switch k {
+		case "type":
+			err = json.Unmarshal(raw, &p.Type)
+		case "title":
+			err = json.Unmarshal(raw, &p.Title)
+		case "status":
+			err = json.Unmarshal(raw, &p.Status)
+		case "detail":
+			err = json.Unmarshal(raw, &p.Detail)
+		case "instance":
+			err = json.Unmarshal(raw, &p.Instance)
+		default:
+			var v any
+			err = json.Unmarshal(raw, &v)
+			if p.Extensions == nil {
+				p.Extensions = map[string]any{}
+			}
+			p.Extensions[k] = v
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProblemResponse writes an error as [Problem] with application/problem+json content type.
+// [Problem] is written as is, [Error] is converted with fields in "errors" extension
+// and its type in "code" extension unless it's an absolute URI.
+// Other errors become a problem with the code and the error message as detail.
+func ProblemResponse(w http.ResponseWriter, code int, err error) {
+	p := newProblem(code, err)
+	if p.Status != 0 {
+		code = p.Status
+	}
+
+	raw, _ := json.MarshalIndent(p, "", "  ")
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(code)
+	w.Write(raw)
+}
+
+func newProblem(code int, err error) *Problem {
+	if err == nil {
+		return &Problem{Title: http.StatusText(code), Status: code}
+	}
+
+	var p *Problem
+	if errors.As(err, &p) && p != nil {
+		copied := *p
+		return &copied
+	}
+
+	e, ok := asError(err)
+	if !ok {
+		e = &Error{Code: code, Message: err.Error()}
+	}
+	code = cmp.Or(e.Code, code)
+
+	p = &Problem{
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: e.Message,
+	}
+
+	// type member is a URI, short codes like "not_found" are put into "code" extension.
+	if u, err := url.Parse(e.Type); err == nil && u.IsAbs() {
+		p.Type = e.Type
+	} else if e.Type != "" {
+		p.Extensions = map[string]any{"code": e.Type}
+	}
+	if len(e.Fields) > 0 {
+		if p.Extensions == nil {
+			p.Extensions = map[string]any{}
+		}
+		p.Extensions["errors"] = e.Fields
+	}
+	return p
+}
+
+// ErrorFormat selects how errors are rendered by [Router].
+type ErrorFormat int
+
+const (
+	// ErrorFormatAuto renders [Problem] if the client accepts application/problem+json
+	// and [Error] otherwise. It's a default.
+	ErrorFormatAuto ErrorFormat = iota
+	// ErrorFormatError always renders [Error] with [ErrorResponse].
+	ErrorFormatError
+	// ErrorFormatProblem always renders [Problem] with [ProblemResponse].
+	ErrorFormatProblem
+)
+
+// NegotiateErrorResponse writes [Problem] if the request accepts application/problem+json
+// and [Error] otherwise.
+func NegotiateErrorResponse(w http.ResponseWriter, r *http.Request, code int, err error) {
+	renderError(w, r, code, err, ErrorFormatAuto)
+}
+
+func renderError(w http.ResponseWriter, r *http.Request, code int, err error, format ErrorFormat) {
+	if format == ErrorFormatProblem || (format == ErrorFormatAuto && acceptsMediaType(r, ProblemContentType)) {
+		ProblemResponse(w, code, err)
+		return
+	}
+	ErrorResponse(w, code, err)
+}
+
+// acceptsMediaType reports whether the media type is explicitly listed in Accept header with non-zero quality.
+func acceptsMediaType(r *http.Request, mediaType string) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for part := range strings.SplitSeq(accept, ",") {
+			typ, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil || typ != mediaType {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// maxErrorBodyBytes limits the body read by [ResponseError].
+const maxErrorBodyBytes = 64 << 10
+
+// ResponseError returns nil for 1xx, 2xx and 3xx responses.
+// Otherwise the body is read and decoded as [*Problem] for application/problem+json,
+// as [*Error] for JSON with httpx error or as [*Error] with the body text as message.
+// Response body is not closed.
+func ResponseError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	if err != nil {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case ProblemContentType:
+		p := &Problem{}
+		if err := json.Unmarshal(body, p); err == nil {
+			p.Status = cmp.Or(p.Status, resp.StatusCode)
+			return p
+		}
+	case "application/json":
+		e := &Error{}
+		if err := json.Unmarshal(body, e); err == nil && e.Message != "" {
+			e.Code = cmp.Or(e.Code, resp.StatusCode)
+			return e
+		}
+	}
+
+	return &Error{
+		Code:    resp.StatusCode,
+		Message: cmp.Or(strings.TrimSpace(string(body)), http.StatusText(resp.StatusCode)),
+	}
+}
@@ -0,0 +1,164 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProblemJSON(t *testing.T) {
+	p := &Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     http.StatusForbidden,
+		Detail:     "Your current balance is 30, but that costs 50.",
+		Instance:   "/account/12345/msgs/abc",
+		Extensions: map[string]any{"balance": 30.0, "accounts": []any{"/account/12345"}},
+	}
+
+	raw, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"accounts":["/account/12345"],"balance":30,"detail":"Your current balance is 30, but that costs 50.",` +
+		`"instance":"/account/12345/msgs/abc","status":403,"title":"You do not have enough credit.",` +
+		`"type":"https://example.com/probs/out-of-credit"}`
+	if string(raw) != want {
+		t.Errorf("marshal\nwant %s\nhave %s", want, raw)
+	}
+
+	var have Problem
+	if err := json.Unmarshal(raw, &have); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&have, p) {
+		t.Errorf("unmarshal\nwant %+v\nhave %+v", p, have)
+	}
+}
+
+func TestProblemResponse(t *testing.T) {
+	r := NewRouter()
+	r.Handle("GET /users", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return &Error{
+			Code:    http.StatusBadRequest,
+			Message: "validation failed",
+			Fields:  []FieldError{{Field: "limit", In: "query", Message: "must be at most 100"}},
+		}
+	}))
+	r.Handle("GET /conflict", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return &Error{Code: http.StatusConflict, Type: "user_exists", Message: "user already exists"}
+	}))
+	r.Handle("GET /credit", HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return &Problem{Type: "https://example.com/probs/out-of-credit", Status: http.StatusForbidden}
+	}))
+
+	tests := []struct {
+		Path     string
+		Accept   string
+		Format   ErrorFormat
+		WantType string
+		WantBody string
+	}{
+		{"/users", "", ErrorFormatAuto, "application/json; charset=utf-8", `"msg": "validation failed"`},
+		{"/users", "application/json, application/problem+json", ErrorFormatAuto, ProblemContentType, `"title": "Bad Request"`},
+		{"/users", "application/problem+json;q=0", ErrorFormatAuto, "application/json; charset=utf-8", `"code": 400`},
+		{"/users", "", ErrorFormatProblem, ProblemContentType, `"errors": [`},
+		{"/credit", "", ErrorFormatProblem, ProblemContentType, `"type": "https://example.com/probs/out-of-credit"`},
+		{"/credit", "", ErrorFormatError, "application/json; charset=utf-8", `"type": "https://example.com/probs/out-of-credit"`},
+		{"/nope", "", ErrorFormatProblem, ProblemContentType, `"status": 404`},
+		{"/conflict", "", ErrorFormatProblem, ProblemContentType, `"code": "user_exists",`},
+		{"/conflict", "", ErrorFormatError, "application/json; charset=utf-8", `"type": "user_exists"`},
+	}
+
+	for _, test := range tests {
+		r.ErrorFormat(test.Format)
+
+		rq := httptest.NewRequest("GET", test.Path, nil)
+		rq.Header.Set("Accept", test.Accept)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, rq)
+
+		if ct := rr.Header().Get("Content-Type"); ct != test.WantType {
+			t.Errorf("%s %q: content type want %q; have %q", test.Path, test.Accept, test.WantType, ct)
+		}
+		if body := rr.Body.String(); !strings.Contains(body, test.WantBody) {
+			t.Errorf("%s %q: body want %q; have %q", test.Path, test.Accept, test.WantBody, body)
+		}
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	newResponse := func(code int, contentType, body string) *http.Response {
+		rr := httptest.NewRecorder()
+		rr.Header().Set("Content-Type", contentType)
+		rr.WriteHeader(code)
+		rr.WriteString(body)
+		return rr.Result()
+	}
+
+	tests := []struct {
+		Resp *http.Response
+		Want error
+	}{
+		{newResponse(http.StatusOK, "application/json", `{}`), nil},
+		{
+			newResponse(http.StatusForbidden, ProblemContentType, `{"title":"Forbidden","status":403,"balance":30}`),
+			&Problem{Title: "Forbidden", Status: 403, Extensions: map[string]any{"balance": 30.0}},
+		},
+		{
+			newResponse(http.StatusNotFound, "application/json; charset=utf-8", `{"code":404,"msg":"user not found"}`),
+			&Error{Code: 404, Message: "user not found"},
+		},
+		{
+			newResponse(http.StatusBadGateway, "text/plain", "upstream is down\n"),
+			&Error{Code: 502, Message: "upstream is down"},
+		},
+	}
+
+	for _, test := range tests {
+		have := ResponseError(test.Resp)
+		if !reflect.DeepEqual(have, test.Want) {
+			t.Errorf("want %#v; have %#v", test.Want, have)
+		}
+	}
+
+	var p *Problem
+	err := ResponseError(newResponse(http.StatusForbidden, ProblemContentType, `{"detail":"no credit"}`))
+	if !errors.As(err, &p) || err.Error() != "no credit" {
+		t.Errorf("want problem; have %v", err)
+	}
+	if p.Status != http.StatusForbidden {
+		t.Errorf("problem status want %d; have %d", http.StatusForbidden, p.Status)
+	}
+}
+
+func TestNewProblemType(t *testing.T) {
+	tests := []struct {
+		Err  error
+		Want *Problem
+	}{
+		{nil, &Problem{Title: "Internal Server Error", Status: 500}},
+		{
+			&Error{Code: http.StatusConflict, Type: "user_exists", Message: "exists"},
+			&Problem{Title: "Conflict", Status: 409, Detail: "exists", Extensions: map[string]any{"code": "user_exists"}},
+		},
+		{
+			&Error{Code: http.StatusConflict, Type: "https://example.com/probs/user-exists", Message: "exists"},
+			&Problem{Type: "https://example.com/probs/user-exists", Title: "Conflict", Status: 409, Detail: "exists"},
+		},
+		{
+			&Error{Code: http.StatusNotFound, Message: "no user"},
+			&Problem{Title: "Not Found", Status: 404, Detail: "no user"},
+		},
+	}
+
+	for _, test := range tests {
+		if have := newProblem(http.StatusInternalServerError, test.Err); !reflect.DeepEqual(have, test.Want) {
+			t.Errorf("want %+v; have %+v", test.Want, have)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyProtocolConfig configures PROXY protocol (v1 and v2) parsing on the listener.
+// See https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt
+type ProxyProtocolConfig struct {
+	// TrustedCIDRs is a list of networks allowed to send PROXY headers.
+	// Connections from other sources are served as-is, without parsing.
+	TrustedCIDRs []netip.Prefix
+
+	// TrustAll allows PROXY headers from any source.
+	// Use only when the listener is reachable by the proxy only,
+	// otherwise any client can spoof its address.
+	TrustAll bool
+
+	// HeaderTimeout limits the time to read the PROXY header.
+	// Default is 5 seconds.
+	HeaderTimeout time.Duration
+}
+
+// ProxyHeader is a parsed PROXY protocol header.
+type ProxyHeader struct {
+	Version     int  // 1 or 2.
+	Local       bool // LOCAL command (v2) or UNKNOWN protocol (v1), addresses are not set.
+	Source      netip.AddrPort
+	Destination netip.AddrPort
+	TLVs        []ProxyTLV // v2 only.
+}
+
+// ProxyTLV is a type-length-value vector from the PROXY protocol v2 header.
+type ProxyTLV struct {
+	Type  byte
+	Value []byte
+}
+
+// PROXY protocol v2 TLV types.
+const (
+	ProxyTLVALPN      byte = 0x01
+	ProxyTLVAuthority byte = 0x02
+	ProxyTLVCRC32C    byte = 0x03
+	ProxyTLVNoop      byte = 0x04
+	ProxyTLVUniqueID  byte = 0x05
+	ProxyTLVSSL       byte = 0x20
+	ProxyTLVNetNS     byte = 0x30
+)
+
+// TLV returns a value of the first TLV with a given type.
+func (h *ProxyHeader) TLV(typ byte) ([]byte, bool) {
+	for _, tlv := range h.TLVs {
+		if tlv.Type == typ {
+			return tlv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// ProxyHeaderFromContext returns the PROXY header of the connection serving the request.
+// Returns nil if PROXY protocol is disabled or the header was not sent.
+func ProxyHeaderFromContext(ctx context.Context) *ProxyHeader {
+	ci := ConnInfoFromContext(ctx)
+	if ci == nil {
+		return nil
+	}
+
+	pc, ok := unwrapConn[*proxyConn](ci.conn)
+	if !ok {
+		return nil
+	}
+	pc.readHeader()
+	return pc.hdr
+}
+
+var (
+	proxySigV1 = []byte("PROXY ")
+	proxySigV2 = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+const proxyV1MaxLen = 107
+
+type proxyListener struct {
+	net.Listener
+	trusted  []netip.Prefix
+	trustAll bool
+	timeout  time.Duration
+}
+
+func newProxyListener(ln net.Listener, cfg *ProxyProtocolConfig) *proxyListener {
+	timeout := cfg.HeaderTimeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	return &proxyListener{
+		Listener: ln,
+		trusted:  cfg.TrustedCIDRs,
+		trustAll: cfg.TrustAll,
+		timeout:  timeout,
+	}
+}
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.isTrusted(conn.RemoteAddr()) {
+		return conn, nil
+	}
+	// header is read lazily to not block the accept loop.
+	return &proxyConn{Conn: conn, timeout: l.timeout}, nil
+}
+
+func (l *proxyListener) isTrusted(addr net.Addr) bool {
+	if l.trustAll {
+		return true
+	}
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := ap.Addr().Unmap()
+	for _, p := range l.trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type proxyConn struct {
+	net.Conn
+	timeout time.Duration
+
+	once sync.Once
+	done atomic.Bool
+	br   *bufio.Reader
+	hdr  *ProxyHeader
+	err  error
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	if c.br.Buffered() > 0 {
+		return c.br.Read(b)
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.hdr == nil || c.hdr.Local {
+		return c.Conn.RemoteAddr()
+	}
+	return net.TCPAddrFromAddrPort(c.hdr.Source)
+}
+
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.readHeader()
+	if c.hdr == nil || c.hdr.Local {
+		return c.Conn.LocalAddr()
+	}
+	return net.TCPAddrFromAddrPort(c.hdr.Destination)
+}
+
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		c.br = bufio.NewReaderSize(c.Conn, 256)
+
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+			c.err = err
+			return
+		}
+		c.hdr, c.err = readProxyHeader(c.br)
+		if err := c.Conn.SetReadDeadline(time.Time{}); err != nil && c.err == nil {
+			c.err = err
+		}
+		if c.err != nil {
+			c.Conn.Close()
+		}
+		c.done.Store(true)
+	})
+}
+
+// readProxyHeader reads PROXY header from a given reader.
+func readProxyHeader(br *bufio.Reader) (*ProxyHeader, error) {
+	sig, err := br.Peek(len(proxySigV1))
+	if err != nil {
+		return nil, fmt.Errorf("httpx: reading PROXY header: %w", err)
+	}
+	if bytes.Equal(sig, proxySigV1) {
+		return readProxyHeaderV1(br)
+	}
+
+	sig, err = br.Peek(len(proxySigV2))
+	if err != nil {
+		return nil, fmt.Errorf("httpx: reading PROXY header: %w", err)
+	}
+	if bytes.Equal(sig, proxySigV2) {
+		return readProxyHeaderV2(br)
+	}
+	return nil, errors.New("httpx: PROXY header is missing")
+}
+
+func readProxyHeaderV1(br *bufio.Reader) (*ProxyHeader, error) {
+	var line []byte
+	for len(line) < proxyV1MaxLen {
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("httpx: reading PROXY v1 header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("httpx: PROXY v1 header is too long")
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	hdr := &ProxyHeader{Version: 1}
+
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		hdr.Local = true
+		return hdr, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("httpx: malformed PROXY v1 header: %q", line)
+	}
+
+	src, err := parseProxyV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseProxyV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, err
+	}
+	if src.Addr().Is4() != (fields[1] == "TCP4") || dst.Addr().Is4() != (fields[1] == "TCP4") {
+		return nil, fmt.Errorf("httpx: PROXY v1 address does not match protocol %s", fields[1])
+	}
+
+	hdr.Source, hdr.Destination = src, dst
+	return hdr, nil
+}
+
+func parseProxyV1Addr(ip, port string) (netip.AddrPort, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("httpx: malformed PROXY v1 address: %w", err)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("httpx: malformed PROXY v1 port: %w", err)
+	}
+	return netip.AddrPortFrom(addr, uint16(p)), nil
+}
+
+func readProxyHeaderV2(br *bufio.Reader) (*ProxyHeader, error) {
+	var head [16]byte
+	if _, err := io.ReadFull(br, head[:]); err != nil {
+		return nil, fmt.Errorf("httpx: reading PROXY v2 header: %w", err)
+	}
+
+	verCmd, famProto := head[12], head[13]
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("httpx: unsupported PROXY version %d", verCmd>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(head[14:16]))
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return nil, fmt.Errorf("httpx: reading PROXY v2 header: %w", err)
+	}
+
+	hdr := &ProxyHeader{Version: 2}
+
+	switch verCmd & 0x0F {
+	case 0x0: // LOCAL
+		hdr.Local = true
+		return hdr, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("httpx: unsupported PROXY v2 command %#x", verCmd&0x0F)
+	}
+
+	var addrLen int
+	switch famProto >> 4 {
+	case 0x0: // AF_UNSPEC
+		hdr.Local = true
+	case 0x1: // AF_INET
+		addrLen = 12
+		if len(payload) < addrLen {
+			return nil, errors.New("httpx: PROXY v2 IPv4 address is truncated")
+		}
+		src := netip.AddrFrom4([4]byte(payload[0:4]))
+		dst := netip.AddrFrom4([4]byte(payload[4:8]))
+		hdr.Source = netip.AddrPortFrom(src, binary.BigEndian.Uint16(payload[8:10]))
+		hdr.Destination = netip.AddrPortFrom(dst, binary.BigEndian.Uint16(payload[10:12]))
+	case 0x2: // AF_INET6
+		addrLen = 36
+		if len(payload) < addrLen {
+			return nil, errors.New("httpx: PROXY v2 IPv6 address is truncated")
+		}
+		src := netip.AddrFrom16([16]byte(payload[0:16]))
+		dst := netip.AddrFrom16([16]byte(payload[16:32]))
+		hdr.Source = netip.AddrPortFrom(src, binary.BigEndian.Uint16(payload[32:34]))
+		hdr.Destination = netip.AddrPortFrom(dst, binary.BigEndian.Uint16(payload[34:36]))
+	case 0x3: // AF_UNIX, addresses are not representable as IP.
+		addrLen = 216
+		if len(payload) < addrLen {
+			return nil, errors.New("httpx: PROXY v2 unix address is truncated")
+		}
+		hdr.Local = true
+	default:
+		return nil, fmt.Errorf("httpx: unsupported PROXY v2 address family %#x", famProto>>4)
+	}
+
+	tlvs, err := parseProxyTLVs(payload[addrLen:])
+	if err != nil {
+		return nil, err
+	}
+	hdr.TLVs = tlvs
+	return hdr, nil
+}
+
+func parseProxyTLVs(b []byte) ([]ProxyTLV, error) {
+	var tlvs []ProxyTLV
+	for len(b) > 0 {
+		if len(b) < 3 {
+			return nil, errors.New("httpx: PROXY v2 TLV is truncated")
+		}
+		n := int(binary.BigEndian.Uint16(b[1:3]))
+		if len(b) < 3+n {
+			return nil, errors.New("httpx: PROXY v2 TLV is truncated")
+		}
+		tlvs = append(tlvs, ProxyTLV{Type: b[0], Value: b[3 : 3+n]})
+		b = b[3+n:]
+	}
+	return tlvs, nil
+}
@@ -0,0 +1,218 @@
+package httpx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadProxyHeader(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   []byte
+		want    *ProxyHeader
+		wantErr bool
+	}{
+		{
+			name:  "v1 tcp4",
+			input: []byte("PROXY TCP4 192.168.0.1 10.0.0.1 56324 443\r\nGET /"),
+			want: &ProxyHeader{
+				Version:     1,
+				Source:      netip.MustParseAddrPort("192.168.0.1:56324"),
+				Destination: netip.MustParseAddrPort("10.0.0.1:443"),
+			},
+		},
+		{
+			name:  "v1 tcp6",
+			input: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 1000 80\r\n"),
+			want: &ProxyHeader{
+				Version:     1,
+				Source:      netip.MustParseAddrPort("[2001:db8::1]:1000"),
+				Destination: netip.MustParseAddrPort("[2001:db8::2]:80"),
+			},
+		},
+		{
+			name:  "v1 unknown",
+			input: []byte("PROXY UNKNOWN\r\n"),
+			want:  &ProxyHeader{Version: 1, Local: true},
+		},
+		{
+			name:    "v1 mismatched family",
+			input:   []byte("PROXY TCP4 2001:db8::1 10.0.0.1 1000 80\r\n"),
+			wantErr: true,
+		},
+		{
+			name:    "v1 too long",
+			input:   []byte("PROXY TCP4 " + strings.Repeat("1", 120) + "\r\n"),
+			wantErr: true,
+		},
+		{
+			name: "v2 tcp4 with tlvs",
+			input: proxyV2(0x21, 0x11,
+				[]byte{192, 168, 0, 1, 10, 0, 0, 1, 0xDC, 0x04, 0x01, 0xBB},
+				[]byte{ProxyTLVALPN, 0, 2, 'h', '2'},
+				[]byte{ProxyTLVAuthority, 0, 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e'},
+			),
+			want: &ProxyHeader{
+				Version:     2,
+				Source:      netip.MustParseAddrPort("192.168.0.1:56324"),
+				Destination: netip.MustParseAddrPort("10.0.0.1:443"),
+				TLVs: []ProxyTLV{
+					{Type: ProxyTLVALPN, Value: []byte("h2")},
+					{Type: ProxyTLVAuthority, Value: []byte("example")},
+				},
+			},
+		},
+		{
+			name:  "v2 local",
+			input: proxyV2(0x20, 0x00),
+			want:  &ProxyHeader{Version: 2, Local: true},
+		},
+		{
+			name:    "v2 truncated tlv",
+			input:   proxyV2(0x21, 0x11, make([]byte, 12), []byte{ProxyTLVNoop, 0, 5, 1}),
+			wantErr: true,
+		},
+		{
+			name:    "no header",
+			input:   []byte("GET / HTTP/1.1\r\n\r\n"),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hdr, err := readProxyHeader(bufio.NewReader(bytes.NewReader(tc.input)))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("have err %v, want err %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(hdr, tc.want) {
+				t.Errorf("\nhave: %+v\nwant: %+v", hdr, tc.want)
+			}
+		})
+	}
+}
+
+func TestProxyConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	pc := &proxyConn{Conn: server, timeout: time.Second}
+	defer pc.Close()
+
+	go client.Write([]byte("PROXY TCP4 192.168.0.1 10.0.0.1 56324 443\r\nhello"))
+
+	if have, want := pc.RemoteAddr().String(), "192.168.0.1:56324"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	body := make([]byte, 5)
+	if _, err := io.ReadFull(pc, body); err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "hello" {
+		t.Errorf("have %q, want %q", body, "hello")
+	}
+}
+
+func TestProxyListener(t *testing.T) {
+	local := netip.MustParsePrefix("127.0.0.0/8")
+	other := netip.MustParsePrefix("10.0.0.0/8")
+
+	tests := []struct {
+		Name       string
+		Config     ProxyProtocolConfig
+		WantRemote string
+		WantBody   string
+	}{
+		{"trusted", ProxyProtocolConfig{TrustedCIDRs: []netip.Prefix{other, local}}, "192.168.0.1:56324", "hello"},
+		{"trust all", ProxyProtocolConfig{TrustAll: true}, "192.168.0.1:56324", "hello"},
+		{"untrusted", ProxyProtocolConfig{TrustedCIDRs: []netip.Prefix{other}}, "127.0.0.1", "PROXY"},
+		{"no trusted", ProxyProtocolConfig{}, "127.0.0.1", "PROXY"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			inner, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ln := newProxyListener(inner, &test.Config)
+			defer ln.Close()
+
+			client, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			go client.Write([]byte("PROXY TCP4 192.168.0.1 10.0.0.1 56324 443\r\nhello"))
+
+			conn, err := ln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			if remote := conn.RemoteAddr().String(); !strings.HasPrefix(remote, test.WantRemote) {
+				t.Errorf("remote addr want %q; have %q", test.WantRemote, remote)
+			}
+			body := make([]byte, 5)
+			if _, err := io.ReadFull(conn, body); err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != test.WantBody {
+				t.Errorf("body want %q; have %q", test.WantBody, body)
+			}
+		})
+	}
+}
+
+func TestConnRemoteAddrNoBlock(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	pc := &proxyConn{Conn: server, timeout: time.Minute}
+	defer pc.Close()
+
+	// silent client must not block, address of the proxy is returned.
+	conn := &limitConn{Conn: pc}
+	if have, want := connRemoteAddr(conn), server.RemoteAddr(); have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	go client.Write([]byte("PROXY TCP4 192.168.0.1 10.0.0.1 56324 443\r\n"))
+	pc.readHeader()
+
+	if have, want := connRemoteAddr(conn).String(), "192.168.0.1:56324"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestProxyConnInvalidHeader(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	pc := &proxyConn{Conn: server, timeout: time.Second}
+	defer pc.Close()
+
+	go client.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+
+	if _, err := pc.Read(make([]byte, 1)); err == nil || !strings.Contains(err.Error(), "PROXY header is missing") {
+		t.Errorf("want missing header error; have %v", err)
+	}
+}
+
+func proxyV2(verCmd, fam byte, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := append([]byte{}, proxySigV2...)
+	b = append(b, verCmd, fam)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(body)))
+	return append(b, body...)
+}
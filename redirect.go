@@ -0,0 +1,106 @@
+package httpx
+
+import (
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HSTSConfig configures Strict-Transport-Security header.
+// See https://developer.mozilla.org/docs/Web/HTTP/Headers/Strict-Transport-Security
+type HSTSConfig struct {
+	// MaxAge of the policy. Default is 1 year.
+	MaxAge            time.Duration
+	IncludeSubDomains bool
+	Preload           bool
+}
+
+func (c *HSTSConfig) header() string {
+	v := "max-age=" + strconv.FormatInt(int64(c.MaxAge/time.Second), 10)
+	if c.IncludeSubDomains {
+		v += "; includeSubDomains"
+	}
+	if c.Preload {
+		v += "; preload"
+	}
+	return v
+}
+
+func hstsHandler(cfg *HSTSConfig, h http.Handler) http.Handler {
+	value := cfg.header()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+const acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+func (s *Server) newRedirectServer() *http.Server {
+	srv := &http.Server{
+		Addr:              s.cfg.RedirectAddr,
+		Handler:           http.HandlerFunc(s.serveRedirect),
+		ReadTimeout:       s.cfg.ReadTimeout,
+		ReadHeaderTimeout: s.cfg.ReadHeaderTimeout,
+		WriteTimeout:      s.cfg.WriteTimeout,
+		IdleTimeout:       s.cfg.IdleTimeout,
+		MaxHeaderBytes:    s.cfg.MaxHeaderBytes,
+	}
+	if s.cfg.Logger != nil {
+		srv.ErrorLog = log.New(&serverLogWriter{logger: s.cfg.Logger.With("server", "redirect")}, "", 0)
+	}
+	return srv
+}
+
+func (s *Server) serveRedirect(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, acmeChallengePrefix):
+		// challenges are never redirected, CA must get them over plain HTTP.
+		if s.acme == nil {
+			statusHandler(http.StatusNotFound).ServeHTTP(w, r)
+			return
+		}
+		s.acme.HTTPHandler(nil).ServeHTTP(w, r)
+
+	case s.cfg.HeartbeatPath != "" && r.URL.Path == s.cfg.HeartbeatPath:
+		if s.cfg.HeartbeatHandler != nil {
+			s.cfg.HeartbeatHandler(w, r)
+		} else {
+			defaultHeartbeatHandler(w, r)
+		}
+
+	default:
+		target, ok := httpsURL(r, s.cfg.Addr)
+		if !ok {
+			ErrorResponse(w, http.StatusBadRequest, errors.New("missing Host header"))
+			return
+		}
+		http.Redirect(w, r, target, s.cfg.RedirectStatus)
+	}
+}
+
+// httpsURL returns the request URL with https scheme and the port of a given TLS address.
+// It reports false if the request has no host.
+func httpsURL(r *http.Request, tlsAddr string) (string, bool) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		return "", false
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	if _, port, err := net.SplitHostPort(tlsAddr); err == nil && port != "" && port != "443" && port != "https" {
+		host += ":" + port
+	}
+	return "https://" + host + r.URL.RequestURI(), true
+}
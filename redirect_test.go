@@ -0,0 +1,80 @@
+package httpx
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServerRedirect(t *testing.T) {
+	srv, err := NewServer(&ServerConfig{
+		Addr:         ":8443",
+		RedirectAddr: ":8080",
+		TLSConfig: &tls.Config{
+			GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return nil, nil },
+		},
+		HSTS:          &HSTSConfig{IncludeSubDomains: true},
+		HeartbeatPath: "/ping",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Target       string
+		WantStatus   int
+		WantLocation string
+	}{
+		{
+			Target:       "http://example.com/foo?bar=1",
+			WantStatus:   http.StatusPermanentRedirect,
+			WantLocation: "https://example.com:8443/foo?bar=1",
+		},
+		{
+			Target:       "http://[::1]:8080/",
+			WantStatus:   http.StatusPermanentRedirect,
+			WantLocation: "https://[::1]:8443/",
+		},
+		{
+			Target:     "http://example.com/.well-known/acme-challenge/token",
+			WantStatus: http.StatusNotFound,
+		},
+		{
+			Target:     "http://example.com/ping",
+			WantStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		srv.redirect.Handler.ServeHTTP(rr, httptest.NewRequest("GET", test.Target, nil))
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s: status want %d; have %d", test.Target, test.WantStatus, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != test.WantLocation {
+			t.Errorf("%s: location want %q; have %q", test.Target, test.WantLocation, loc)
+		}
+	}
+
+	rq := httptest.NewRequest("GET", "/foo", nil)
+	rq.Host = ""
+	rr := httptest.NewRecorder()
+	srv.redirect.Handler.ServeHTTP(rr, rq)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("no host: status want %d; have %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rq = httptest.NewRequest("GET", "https://example.com/", nil)
+	rr = httptest.NewRecorder()
+	srv.srv.Handler.ServeHTTP(rr, rq)
+
+	want := "max-age=31536000; includeSubDomains"
+	if have := rr.Header().Get("Strict-Transport-Security"); have != want {
+		t.Errorf("HSTS want %q; have %q", want, have)
+	}
+}
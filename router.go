@@ -1,8 +1,16 @@
 package httpx
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
 	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
 	"slices"
+	"strings"
 )
 
 // Router for net/http.
@@ -11,12 +19,78 @@ type Router struct {
 	globalMw    []func(http.Handler) http.Handler
 	routeMw     []func(http.Handler) http.Handler
 	isSubRouter bool
+	prefix      string    // added to patterns, see [Router.Route].
+	meta        RouteMeta // see [Router.WithMeta].
+	routes      *[]route  // shared with subrouters.
+	config      *routerConfig
+	hosts       *[]*hostRoute // shared with subrouters, see [Router.Host].
+	versioning  *versioning   // shared with subrouters, see [Router.Version].
+}
+
+// routerConfig is shared by a router and its subrouters.
+type routerConfig struct {
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+	options          http.Handler
+	errorHandler     func(w http.ResponseWriter, r *http.Request, err error)
+	errorMappings    []errorMapping
+	errorFormat      ErrorFormat
+}
+
+type route struct {
+	info  RouteInfo
+	mount *Router // mounted router, if any.
+}
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Pattern   string   `json:"pattern"`
+	Method    string   `json:"method,omitempty"`
+	Host      string   `json:"host,omitempty"`
+	Path      string   `json:"path"`
+	Wildcards []string `json:"wildcards,omitempty"`
+	// Middlewares are function names of global and route middlewares in the order of execution.
+	Middlewares []string  `json:"middlewares,omitempty"`
+	Meta        RouteMeta `json:"meta"`
+	// Version of API, see [Router.Version].
+	Version string `json:"version,omitempty"`
+}
+
+// RouteMeta is an optional description of a route.
+type RouteMeta struct {
+	Summary string   `json:"summary,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	// Auth requirement: "basic", "bearer", "digest" or "mtls", empty for public routes.
+	Auth string `json:"auth,omitempty"`
+
+	// Request body type, like reflect.TypeFor[CreateUser](). Used by [Router.OpenAPI].
+	Request reflect.Type `json:"-"`
+	// Responses by status code, nil type is a response without body.
+	Responses map[int]reflect.Type `json:"-"`
+	// Errors are status codes of [Error] responses.
+	Errors []int `json:"errors,omitempty"`
+	// Params in query, header or cookie. Path wildcards are added automatically.
+	Params []Param `json:"-"`
+}
+
+// Param of a request.
+type Param struct {
+	Name string
+	In   string // "path", "query", "header" or "cookie".
+	// Type of the value, string if nil.
+	Type        reflect.Type
+	Required    bool
+	Description string
 }
 
 // NewRouter creates a new [Router]
 func NewRouter() *Router {
 	return &Router{
-		mux: http.NewServeMux(),
+		mux:        http.NewServeMux(),
+		routes:     new([]route),
+		config:     &routerConfig{},
+		hosts:      new([]*hostRoute),
+		versioning: &versioning{},
 	}
 }
 
@@ -31,12 +105,114 @@ func (r *Router) Use(mws ...func(http.Handler) http.Handler) {
 
 // Group creates a new subrouter inheriting global middlewares.
 func (r *Router) Group(fn func(r *Router)) {
-	subRouter := &Router{
+	fn(r.subRouter(r.prefix))
+}
+
+// Route creates a new subrouter like [Router.Group] which adds prefix to all the patterns.
+// Prefix is a path optionally preceded by a host, like "/api" or "example.com/api".
+// Method and host of the registered patterns are kept: "GET /users" becomes "GET /api/users".
+func (r *Router) Route(prefix string, fn func(r *Router)) {
+	if method, _, _ := splitPattern(prefix); method != "" {
+		panic("httpx: route prefix must not contain a method: " + prefix)
+	}
+	fn(r.subRouter(joinPattern(r.prefix, prefix)))
+}
+
+func (r *Router) subRouter(prefix string) *Router {
+	return &Router{
 		mux:         r.mux,
 		routeMw:     slices.Clone(r.routeMw),
 		isSubRouter: true,
+		prefix:      prefix,
+		meta:        r.meta,
+		routes:      r.routes,
+		config:      r.config,
+		hosts:       r.hosts,
+		versioning:  r.versioning,
+	}
+}
+
+// WithMeta returns a subrouter which adds meta to the routes registered on it.
+//
+//	r.WithMeta(httpx.RouteMeta{Summary: "Get user", Tags: []string{"users"}}).
+//		HandleFunc("GET /users/{id}", getUser)
+func (r *Router) WithMeta(meta RouteMeta) *Router {
+	sub := r.subRouter(r.prefix)
+	sub.meta = meta
+	return sub
+}
+
+// Mount handler under the prefix for all methods.
+// Prefix is removed from the request path, wildcards from the prefix
+// are still available via [http.Request.PathValue].
+// For a mounted [Router] [http.Request.Pattern] contains the full pattern.
+func (r *Router) Mount(prefix string, h http.Handler) {
+	pattern := joinPattern(r.prefix, prefix)
+	_, _, path := splitPattern(pattern)
+	path = strings.TrimSuffix(path, "/")
+
+	segments := strings.Count(path, "/")
+	names := pathWildcards(path)
+
+	sub, _ := h.(*Router)
+	r.handle(strings.TrimSuffix(prefix, "/")+"/", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(w, mountRequest(req, segments, names))
+	}), sub)
+}
+
+type (
+	mountKey       struct{}
+	mountValuesKey struct{}
+)
+
+// mountRequest returns a request without the first segments of the path.
+// Values of names are kept in the context to be set again
+// after a mounted router matches its own pattern, see [mountedPattern].
+func mountRequest(r *http.Request, segments int, names []string) *http.Request {
+	outer, _ := r.Context().Value(mountValuesKey{}).(map[string]string)
+	values := maps.Clone(outer)
+	for _, name := range names {
+		if values == nil {
+			values = map[string]string{}
+		}
+		values[name] = r.PathValue(name)
+	}
+
+	// r.Pattern is already a full pattern of the mount point.
+	ctx := context.WithValue(r.Context(), mountKey{}, strings.TrimSuffix(r.Pattern, "/"))
+	if values != nil {
+		ctx = context.WithValue(ctx, mountValuesKey{}, values)
+	}
+
+	r2 := r.Clone(ctx)
+	stripSegments(r2.URL, segments)
+	return r2
+}
+
+// stripSegments removes the first segments of the escaped path,
+// so escaped slashes like %2F are not counted as separators.
+func stripSegments(u *url.URL, segments int) {
+	escaped := u.EscapedPath()
+	i := 0
+	for range segments {
+		j := strings.IndexByte(escaped[i+1:], '/')
+		if j == -1 {
+			i = len(escaped)
+			break
+		}
+		i += j + 1
+	}
+	escaped = cmp.Or(escaped[i:], "/")
+
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		// escaped path is already validated by url.Parse.
+		path = escaped
+	}
+	u.Path, u.RawPath = path, ""
+	if u.EscapedPath() != escaped {
+		u.RawPath = escaped
 	}
-	fn(subRouter)
 }
 
 // HandleFunc add pattern to the router or subrouter.
@@ -46,18 +222,333 @@ func (r *Router) HandleFunc(pattern string, h http.HandlerFunc) {
 
 // Handle add pattern to the router or subrouter.
 func (r *Router) Handle(pattern string, h http.Handler) {
+	r.handle(pattern, h, nil)
+}
+
+// handle registers the pattern, mount is a mounted router, if any.
+func (r *Router) handle(pattern string, h http.Handler, mount *Router) {
+	meta := r.meta
+	if typed, ok := h.(interface{ routeMeta(*RouteMeta) }); ok {
+		meta.Params = slices.Clone(meta.Params)
+		typed.routeMeta(&meta)
+	}
+
+	if eh, ok := h.(errorServer); ok {
+		config := r.config
+		h = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if err := eh.serveError(w, req); err != nil {
+				config.handleError(w, req, err)
+			}
+		})
+	}
+
 	for _, mw := range slices.Backward(r.routeMw) {
 		h = mw(h)
 	}
-	r.mux.Handle(pattern, h)
+	if r.prefix != "" {
+		pattern = joinPattern(r.prefix, pattern)
+	}
+	r.mux.Handle(pattern, mountedPattern(h))
+
+	info := newRouteInfo(pattern)
+	info.Middlewares = funcNames(r.routeMw)
+	info.Meta = meta
+	*r.routes = append(*r.routes, route{info: info, mount: mount})
+}
+
+// mountedPattern sets full [http.Request.Pattern] when router is mounted.
+// Path values of the mount point are set again, wildcards of the matched pattern take precedence.
+func mountedPattern(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if values, ok := r.Context().Value(mountValuesKey{}).(map[string]string); ok {
+			names := pathWildcards(r.Pattern)
+			for name, value := range values {
+				if !slices.Contains(names, name) {
+					r.SetPathValue(name, value)
+				}
+			}
+		}
+		if mount, ok := r.Context().Value(mountKey{}).(string); ok {
+			r.Pattern = joinPattern(mount, r.Pattern)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Patterns returns registered patterns in the order of registration.
+func (r *Router) Patterns() []string {
+	patterns := make([]string, 0, len(*r.routes))
+	for _, rt := range *r.routes {
+		patterns = append(patterns, rt.info.Pattern)
+	}
+	return patterns
+}
+
+// Routes returns registered routes in the order of registration.
+// Routes of a mounted [Router] are listed instead of its mount point.
+// Routes of [Router.Host] and [Router.Version] routers are listed last.
+func (r *Router) Routes() []RouteInfo {
+	global := funcNames(r.globalMw)
+
+	routes := make([]RouteInfo, 0, len(*r.routes))
+	for _, rt := range *r.routes {
+		info := rt.info
+		info.Middlewares = slices.Concat(global, info.Middlewares)
+
+		if rt.mount == nil {
+			routes = append(routes, info)
+			continue
+		}
+
+		mount := strings.TrimSuffix(info.Pattern, "/")
+		for _, sub := range rt.mount.Routes() {
+			subInfo := newRouteInfo(joinPattern(mount, sub.Pattern))
+			subInfo.Middlewares = slices.Concat(info.Middlewares, sub.Middlewares)
+			subInfo.Version = sub.Version
+			subInfo.Meta = sub.Meta
+			if reflect.ValueOf(sub.Meta).IsZero() {
+				subInfo.Meta = info.Meta
+			}
+			routes = append(routes, subInfo)
+		}
+	}
+
+	for _, hr := range *r.hosts {
+		for _, sub := range hr.router.Routes() {
+			info := newRouteInfo(joinPattern(hr.pattern, sub.Pattern))
+			info.Middlewares = slices.Concat(global, sub.Middlewares)
+			info.Meta = sub.Meta
+			routes = append(routes, info)
+		}
+	}
+
+	for _, vr := range r.versioning.versions {
+		for _, info := range vr.router.Routes() {
+			info.Middlewares = slices.Concat(global, info.Middlewares)
+			info.Version = vr.version.Name
+			routes = append(routes, info)
+		}
+	}
+	return routes
+}
+
+func newRouteInfo(pattern string) RouteInfo {
+	method, host, path := splitPattern(pattern)
+	return RouteInfo{
+		Pattern:   pattern,
+		Method:    method,
+		Host:      host,
+		Path:      path,
+		Wildcards: pathWildcards(path),
+	}
+}
+
+// pathWildcards returns names of wildcards like {id} and {path...}.
+func pathWildcards(path string) []string {
+	var names []string
+	for seg := range strings.SplitSeq(path, "/") {
+		name, ok := strings.CutPrefix(seg, "{")
+		if !ok || name == "$}" {
+			continue
+		}
+		name = strings.TrimSuffix(name, "}")
+		names = append(names, strings.TrimSuffix(name, "..."))
+	}
+	return names
+}
+
+func funcNames(fns []func(http.Handler) http.Handler) []string {
+	if len(fns) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(fns))
+	for _, fn := range fns {
+		names = append(names, runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name())
+	}
+	return names
+}
+
+// NotFound sets a handler for requests without a matching pattern.
+// Global middlewares are applied to it. Default is 404 Not Found in [Router.ErrorFormat].
+func (r *Router) NotFound(h http.Handler) {
+	r.config.notFound = h
+}
+
+// MethodNotAllowed sets a handler for requests matching a pattern with another method.
+// Allow header is set before the handler is called. Global middlewares are applied to it.
+// Default is 405 Method Not Allowed in [Router.ErrorFormat].
+func (r *Router) MethodNotAllowed(h http.Handler) {
+	r.config.methodNotAllowed = h
+}
+
+// Options sets a handler for OPTIONS requests to paths without an explicit OPTIONS route,
+// for example to answer CORS preflight requests.
+// Allow header with methods of the path is set before the handler is called.
+// Default is 204 No Content. HEAD is served by GET routes.
+func (r *Router) Options(h http.Handler) {
+	r.config.options = h
 }
 
+// ErrorHandler sets a handler for errors returned by [HandlerFunc] and [Handler].
+// Use [Router.ErrorStatus] to get a status code of the error.
+// By default errors are rendered with [ErrorResponse], unknown errors are logged
+// and rendered as 500 Internal Server Error without details,
+// nothing is written for requests canceled by the client.
+func (r *Router) ErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) {
+	r.config.errorHandler = fn
+}
+
+// MapError sets status code for errors matching target with [errors.Is].
+//
+//	r.MapError(sql.ErrNoRows, http.StatusNotFound)
+func (r *Router) MapError(target error, code int) {
+	r.config.errorMappings = append(r.config.errorMappings, errorMapping{target: target, code: code})
+}
+
+// ErrorStatus returns status code of the error returned by a handler:
+// from [Router.MapError], [Error] code, [StatusClientClosedRequest] for requests
+// canceled by the client or 500 Internal Server Error.
+func (r *Router) ErrorStatus(req *http.Request, err error) int {
+	return errorStatus(r.config.errorMappings, req, err)
+}
+
+func (c *routerConfig) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if c.errorHandler != nil {
+		c.errorHandler(w, r, err)
+		return
+	}
+	defaultErrorHandler(c.errorMappings, c.errorFormat)(w, r, err)
+}
+
+// ErrorFormat sets format of errors rendered by the router, default is [ErrorFormatAuto].
+func (r *Router) ErrorFormat(format ErrorFormat) {
+	r.config.errorFormat = format
+}
+
+// statusError renders err or status text if err is nil.
+func (c *routerConfig) statusError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	if err == nil {
+		err = errors.New(http.StatusText(code))
+	}
+	renderError(w, r, code, err, c.errorFormat)
+}
+
+// dispatch request to the mux or to not found and method not allowed handlers.
+func (r *Router) dispatch(w http.ResponseWriter, req *http.Request) {
+	if r.serveHost(w, req) || r.serveVersion(w, req) {
+		return
+	}
+	if req.RequestURI == "*" {
+		r.mux.ServeHTTP(w, req)
+		return
+	}
+
+	h, pattern := r.mux.Handler(req)
+	if pattern != "" {
+		r.mux.ServeHTTP(w, req)
+		return
+	}
+
+	// mux returns either 404 or 405 handler with Allow header.
+	rec := &headerRecorder{header: http.Header{}}
+	h.ServeHTTP(rec, req)
+
+	if rec.code == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", allowOptions(rec.header.Get("Allow")))
+		if req.Method == http.MethodOptions {
+			r.serveOptions(w, req)
+			return
+		}
+		if r.config.methodNotAllowed == nil {
+			r.config.statusError(w, req, http.StatusMethodNotAllowed, nil)
+			return
+		}
+		r.config.methodNotAllowed.ServeHTTP(w, req)
+		return
+	}
+
+	if r.config.notFound == nil {
+		r.config.statusError(w, req, http.StatusNotFound, nil)
+		return
+	}
+	r.config.notFound.ServeHTTP(w, req)
+}
+
+// hasRoute reports whether the request path matches a route with any method.
+func (r *Router) hasRoute(req *http.Request) bool {
+	h, pattern := r.mux.Handler(req)
+	if pattern != "" {
+		return true
+	}
+	rec := &headerRecorder{header: http.Header{}}
+	h.ServeHTTP(rec, req)
+	return rec.code == http.StatusMethodNotAllowed
+}
+
+func (r *Router) serveOptions(w http.ResponseWriter, req *http.Request) {
+	if r.config.options == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	r.config.options.ServeHTTP(w, req)
+}
+
+// allowOptions adds OPTIONS to the sorted list of allowed methods.
+func allowOptions(allow string) string {
+	methods := []string{http.MethodOptions}
+	for m := range strings.SplitSeq(allow, ",") {
+		if m = strings.TrimSpace(m); m != "" && m != http.MethodOptions {
+			methods = append(methods, m)
+		}
+	}
+	slices.Sort(methods)
+	return strings.Join(methods, ", ")
+}
+
+// headerRecorder records status code and headers and discards the body.
+type headerRecorder struct {
+	header http.Header
+	code   int
+}
+
+func (rec *headerRecorder) Header() http.Header         { return rec.header }
+func (rec *headerRecorder) Write(b []byte) (int, error) { return len(b), nil }
+func (rec *headerRecorder) WriteHeader(code int)        { rec.code = code }
+
 // ServeHTTP implements [http.Handler]
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	var h http.Handler = r.mux
+	var h http.Handler = http.HandlerFunc(r.dispatch)
 
 	for _, mw := range slices.Backward(r.globalMw) {
 		h = mw(h)
 	}
 	h.ServeHTTP(w, req)
 }
+
+// splitPattern into parts of "[METHOD ][HOST]/[PATH]".
+func splitPattern(pattern string) (method, host, path string) {
+	rest := strings.TrimSpace(pattern)
+	if i := strings.IndexAny(rest, " \t"); i != -1 {
+		method, rest = rest[:i], strings.TrimLeft(rest[i:], " \t")
+	}
+	if i := strings.IndexByte(rest, '/'); i != -1 {
+		return method, rest[:i], rest[i:]
+	}
+	return method, rest, ""
+}
+
+// joinPattern adds prefix to the host and path of the pattern.
+// Method and host of the pattern take precedence over the prefix ones.
+func joinPattern(prefix, pattern string) string {
+	pm, ph, pp := splitPattern(prefix)
+	method, host, path := splitPattern(pattern)
+
+	method = cmp.Or(method, pm)
+	host = cmp.Or(host, ph)
+	path = strings.TrimSuffix(pp, "/") + path
+
+	if method != "" {
+		return method + " " + host + path
+	}
+	return host + path
+}
@@ -1,8 +1,12 @@
 package httpx
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -112,7 +116,7 @@ func TestRouter(t *testing.T) {
 			WantUsed:   "12",
 			WantStatus: http.StatusOK,
 		},
-		// Check global middleware used on errors generated by http.ServeMux
+		// Check global middleware used on not found and method not allowed handlers
 		{
 			Method:     "GET",
 			Path:       "/notfound",
@@ -153,3 +157,233 @@ func TestRouter(t *testing.T) {
 		}
 	}
 }
+
+func TestRouterRouteMount(t *testing.T) {
+	hf := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s|%s|%s", r.Pattern, r.URL.Path, r.PathValue("tenant"), r.PathValue("id"))
+	}
+
+	users := NewRouter()
+	users.HandleFunc("GET /{id}", hf)
+	users.HandleFunc("GET /{$}", hf)
+
+	r := NewRouter()
+	r.Route("/api", func(r *Router) {
+		r.HandleFunc("GET /status", hf)
+		r.HandleFunc("POST example.com/hosted", hf)
+
+		r.Route("/tenants/{tenant}", func(r *Router) {
+			r.Mount("/users", users)
+		})
+	})
+	r.Mount("/static/", http.HandlerFunc(hf))
+
+	wantPatterns := []string{
+		"GET /api/status",
+		"POST example.com/api/hosted",
+		"/api/tenants/{tenant}/users/",
+		"/static/",
+	}
+	if have := r.Patterns(); !slices.Equal(have, wantPatterns) {
+		t.Errorf("patterns want %q; have %q", wantPatterns, have)
+	}
+
+	tests := []struct {
+		Method string
+		URL    string
+		Want   string
+	}{
+		{"GET", "/api/status", "GET /api/status|/api/status||"},
+		{"POST", "http://example.com/api/hosted", "POST example.com/api/hosted|/api/hosted||"},
+		{"GET", "/api/tenants/acme/users/42", "GET /api/tenants/{tenant}/users/{id}|/42|acme|42"},
+		{"GET", "/api/tenants/acme/users/", "GET /api/tenants/{tenant}/users/{$}|/|acme|"},
+		{"GET", "/api/tenants/a%2Fb/users/42", "GET /api/tenants/{tenant}/users/{id}|/42|a/b|42"},
+		{"GET", "/static/css/app.css", "/static/|/css/app.css||"},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(test.Method, test.URL, nil))
+
+		if have := rr.Body.String(); have != test.Want {
+			t.Errorf("%s %s: want %q; have %q", test.Method, test.URL, test.Want, have)
+		}
+	}
+}
+
+func TestRouterMountEscapedPath(t *testing.T) {
+	r := NewRouter()
+	r.Mount("/files/{bucket}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s|%s", r.URL.Path, r.URL.RawPath, r.PathValue("bucket"))
+	}))
+
+	tests := []struct {
+		URL  string
+		Want string
+	}{
+		{"/files/a/x/y", "/x/y||a"},
+		{"/files/a%2Fb/x%2Fy/z", "/x/y/z|/x%2Fy/z|a/b"},
+		{"/files/a%2Fb/", "/||a/b"},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", test.URL, nil))
+
+		if have := rr.Body.String(); have != test.Want {
+			t.Errorf("%s: want %q; have %q", test.URL, test.Want, have)
+		}
+	}
+}
+
+func TestRouterRoutes(t *testing.T) {
+	mw := func(next http.Handler) http.Handler { return next }
+
+	users := NewRouter()
+	users.WithMeta(RouteMeta{Summary: "Get user", Tags: []string{"users"}}).
+		HandleFunc("GET /{id}", NoopHandler)
+	users.Version(Version{Name: "2"}, func(r *Router) {
+		r.HandleFunc("GET /{id}", NoopHandler)
+	})
+
+	r := NewRouter()
+	r.Use(mw)
+	r.HandleFunc("GET /files/{path...}", NoopHandler)
+	r.Group(func(r *Router) {
+		r.Use(ClientCertMiddleware(nil))
+		r.WithMeta(RouteMeta{Auth: "mtls"}).Mount("/tenants/{tenant}/users", users)
+	})
+
+	mwName := "github.com/cristalhq/httpx.TestRouterRoutes.func1"
+	certName := "github.com/cristalhq/httpx.ClientCertMiddleware.func1"
+
+	want := []RouteInfo{
+		{
+			Pattern:     "GET /files/{path...}",
+			Method:      "GET",
+			Path:        "/files/{path...}",
+			Wildcards:   []string{"path"},
+			Middlewares: []string{mwName},
+		},
+		{
+			Pattern:     "GET /tenants/{tenant}/users/{id}",
+			Method:      "GET",
+			Path:        "/tenants/{tenant}/users/{id}",
+			Wildcards:   []string{"tenant", "id"},
+			Middlewares: []string{mwName, certName},
+			Meta:        RouteMeta{Summary: "Get user", Tags: []string{"users"}},
+		},
+		{
+			Pattern:     "GET /tenants/{tenant}/users/{id}",
+			Method:      "GET",
+			Path:        "/tenants/{tenant}/users/{id}",
+			Wildcards:   []string{"tenant", "id"},
+			Middlewares: []string{mwName, certName},
+			Meta:        RouteMeta{Auth: "mtls"},
+			Version:     "2",
+		},
+	}
+
+	if have := r.Routes(); !reflect.DeepEqual(have, want) {
+		t.Errorf("routes\nwant %+v\nhave %+v", want, have)
+	}
+}
+
+func TestRouterNotFound(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("GET /users/{id}", NoopHandler)
+	r.HandleFunc("DELETE /users/{id}", NoopHandler)
+
+	tests := []struct {
+		Method     string
+		Path       string
+		WantStatus int
+		WantAllow  string
+		WantBody   string
+	}{
+		{"GET", "/nope", http.StatusNotFound, "", `"msg": "Not Found"`},
+		{"POST", "/users/1", http.StatusMethodNotAllowed, "DELETE, GET, HEAD, OPTIONS", `"msg": "Method Not Allowed"`},
+		{"GET", "/users//1", http.StatusTemporaryRedirect, "", ""},
+	}
+
+	check := func(name string) {
+		for _, test := range tests {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(test.Method, test.Path, nil))
+
+			if rr.Code != test.WantStatus {
+				t.Errorf("%s %s %s: status want %d; have %d", name, test.Method, test.Path, test.WantStatus, rr.Code)
+			}
+			if allow := rr.Header().Get("Allow"); allow != test.WantAllow {
+				t.Errorf("%s %s %s: Allow want %q; have %q", name, test.Method, test.Path, test.WantAllow, allow)
+			}
+			if body := rr.Body.String(); !strings.Contains(body, test.WantBody) {
+				t.Errorf("%s %s %s: body want %q; have %q", name, test.Method, test.Path, test.WantBody, body)
+			}
+		}
+	}
+	check("default")
+
+	r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		MarshalResponse(w, http.StatusNotFound, map[string]string{"error": "no route"})
+	}))
+	r.MethodNotAllowed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		MarshalResponse(w, http.StatusMethodNotAllowed, map[string]string{"error": "bad method"})
+	}))
+	tests[0].WantBody = `{"error":"no route"}`
+	tests[1].WantBody = `{"error":"bad method"}`
+	check("custom")
+}
+
+func TestRouterOptions(t *testing.T) {
+	r := NewRouter()
+	r.HandleFunc("GET /users/{id}", NoopHandler)
+	r.HandleFunc("PUT /users/{id}", NoopHandler)
+	r.HandleFunc("OPTIONS /files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusOK)
+	})
+	users := NewRouter()
+	users.HandleFunc("POST /{id}", NoopHandler)
+	r.Mount("/tenants/{tenant}/users", users)
+
+	tests := []struct {
+		Method     string
+		Path       string
+		WantStatus int
+		WantAllow  string
+	}{
+		{"OPTIONS", "/users/1", http.StatusNoContent, "GET, HEAD, OPTIONS, PUT"},
+		{"HEAD", "/users/1", http.StatusOK, ""},
+		{"OPTIONS", "/files/a.txt", http.StatusOK, "GET"},
+		{"OPTIONS", "/tenants/acme/users/1", http.StatusNoContent, "OPTIONS, POST"},
+		{"OPTIONS", "/nope", http.StatusNotFound, ""},
+	}
+
+	check := func(name string) {
+		for _, test := range tests {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(test.Method, test.Path, nil))
+
+			if rr.Code != test.WantStatus {
+				t.Errorf("%s %s %s: status want %d; have %d", name, test.Method, test.Path, test.WantStatus, rr.Code)
+			}
+			if allow := rr.Header().Get("Allow"); allow != test.WantAllow {
+				t.Errorf("%s %s %s: Allow want %q; have %q", name, test.Method, test.Path, test.WantAllow, allow)
+			}
+		}
+	}
+	check("default")
+
+	r.Options(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Methods", w.Header().Get("Allow"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	check("custom")
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("OPTIONS", "/users/1", nil))
+	if have := rr.Header().Get("Access-Control-Allow-Methods"); have != "GET, HEAD, OPTIONS, PUT" {
+		t.Errorf("preflight methods have %q", have)
+	}
+}
@@ -0,0 +1,61 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// RunGroup runs servers and background functions under one context.
+// When any of them fails, the rest are stopped via context cancellation.
+// Zero value is ready to use.
+type RunGroup struct {
+	fns []func(ctx context.Context) error
+}
+
+// Add a function to the group. It must return when the context is canceled.
+func (g *RunGroup) Add(fn func(ctx context.Context) error) {
+	g.fns = append(g.fns, fn)
+}
+
+// AddServer adds servers to the group, see [Server.Run].
+func (g *RunGroup) AddServer(srvs ...*Server) {
+	for _, srv := range srvs {
+		g.Add(srv.Run)
+	}
+}
+
+// Run all the functions and wait for them to return.
+// Errors are aggregated with [errors.Join], cancellation errors caused by
+// the group shutdown are omitted.
+func (g *RunGroup) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(g.fns))
+
+	var wg sync.WaitGroup
+	for i, fn := range g.fns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("httpx: panic in run group: %v", r)
+					cancel()
+				}
+			}()
+
+			err := fn(ctx)
+			if err == nil || (ctx.Err() != nil && errors.Is(err, context.Canceled)) {
+				return
+			}
+			errs[i] = err
+			cancel()
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -3,15 +3,37 @@ package httpx
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Server for HTTP protocol.
 type Server struct {
-	srv *http.Server
-	cfg *ServerConfig
+	srv      *http.Server
+	cfg      *ServerConfig
+	redirect *http.Server
+	acme     *ACMEManager
+	limiter  *connLimiter
+
+	onStart    []func(ctx context.Context) error
+	onReady    []func(ctx context.Context)
+	onShutdown []func(ctx context.Context)
+
+	mu sync.Mutex
+	ln net.Listener
+
+	conns      sync.Map // net.Conn -> *ConnInfo
+	nextConnID atomic.Uint64
+	connStats  connCounters
 }
 
 // ServerConfig configures Server.
@@ -19,10 +41,37 @@ type ServerConfig struct {
 	Addr    string
 	Handler http.Handler
 
-	NoHTTP2   bool
+	// Protocols is the set of protocols accepted by the server.
+	// If nil, HTTP/1 and HTTP/2 over TLS are enabled.
+	// Use [http.Protocols.SetUnencryptedHTTP2] to accept HTTP/2 with prior knowledge (h2c).
+	Protocols *http.Protocols
+
+	// NoHTTP2 disables HTTP/2 both over TLS and unencrypted regardless of Protocols.
+	NoHTTP2 bool
+
+	// HTTP2 configures HTTP/2 connections (max concurrent streams, frame sizes, etc.).
+	// If nil, defaults are used.
+	HTTP2 *http.HTTP2Config
+
+	// TLSConfig enables TLS when set.
 	TLSConfig *tls.Config
 
-	// TODO: enable option
+	// ACME enables TLS with certificates obtained and renewed automatically.
+	// If TLSConfig is also set, its GetCertificate is replaced.
+	ACME *ACMEConfig
+
+	// RedirectAddr starts a companion plain HTTP server on a given address
+	// which redirects all requests to HTTPS, except ACME challenges and HeartbeatPath.
+	// ACME challenges (/.well-known/acme-challenge/) are answered when ACME is set
+	// and 404 Not Found otherwise. Requires TLSConfig or ACME.
+	RedirectAddr string
+	// RedirectStatus for HTTP to HTTPS redirects. Default is 308 Permanent Redirect.
+	RedirectStatus int
+
+	// HSTS sets Strict-Transport-Security header on TLS responses. Disabled if nil.
+	HSTS *HSTSConfig
+
+	// HeartbeatPath is answered by HeartbeatHandler on the redirect server.
 	HeartbeatPath    string
 	HeartbeatHandler http.HandlerFunc
 
@@ -31,9 +80,39 @@ type ServerConfig struct {
 	WriteTimeout      time.Duration
 	IdleTimeout       time.Duration
 	MaxHeaderBytes    int
+
+	// ShutdownTimeout limits graceful shutdown. Default is 1 second.
+	ShutdownTimeout time.Duration
+
+	// ProxyProtocol enables PROXY protocol parsing on connections accepted by Addr listener,
+	// so the real client address is reported in [http.Request.RemoteAddr].
+	// Disabled if nil.
+	ProxyProtocol *ProxyProtocolConfig
+
+	// MaxConns limits the number of concurrent connections. No limit if 0.
+	MaxConns int
+	// MaxConnsPerIP limits the number of concurrent connections from a single client IP. No limit if 0.
+	// Connections over the limit are rejected immediately.
+	MaxConnsPerIP int
+	// ConnLimitMode selects how connections over MaxConns are handled.
+	// Default is [ConnLimitQueue] if ConnQueueTimeout is set and [ConnLimitReject] otherwise.
+	// Rejected connections are answered with 503 Service Unavailable on plain HTTP,
+	// TLS connections are closed without a response.
+	ConnLimitMode ConnLimitMode
+	// ConnQueueTimeout is how long the listener waits for a free slot in [ConnLimitQueue] mode,
+	// meanwhile new connections stay in the kernel backlog.
+	ConnQueueTimeout time.Duration
+
+	// Logger for server errors like TLS handshake failures and handler panics.
+	// If nil, errors are written to stderr by the standard logger.
+	Logger *slog.Logger
+	// LogConnState logs connection state transitions at debug level.
+	// Uses Logger or [slog.Default] if it's nil.
+	LogConnState bool
 }
 
-// Validate the config.
+// Validate the config and set defaults for zero values.
+// All the problems are reported at once via [errors.Join].
 func (c *ServerConfig) Validate() error {
 	if c.ReadTimeout == 0 {
 		c.ReadTimeout = 30 * time.Second
@@ -50,9 +129,139 @@ func (c *ServerConfig) Validate() error {
 	if c.MaxHeaderBytes == 0 {
 		c.MaxHeaderBytes = 8 * 1024
 	}
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = time.Second
+	}
+	if c.RedirectAddr != "" && c.RedirectStatus == 0 {
+		c.RedirectStatus = http.StatusPermanentRedirect
+	}
+	if c.HSTS != nil && c.HSTS.MaxAge == 0 {
+		c.HSTS.MaxAge = 365 * 24 * time.Hour
+	}
+	if c.ConnLimitMode == "" {
+		c.ConnLimitMode = ConnLimitReject
+		if c.ConnQueueTimeout > 0 {
+			c.ConnLimitMode = ConnLimitQueue
+		}
+	}
+
+	var errs []error
+	if c.Addr != "" {
+		if err := validateAddr(c.Addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	type field struct {
+		name  string
+		value int64
+	}
+	nonNegative := []field{
+		{"ReadTimeout", int64(c.ReadTimeout)},
+		{"ReadHeaderTimeout", int64(c.ReadHeaderTimeout)},
+		{"WriteTimeout", int64(c.WriteTimeout)},
+		{"IdleTimeout", int64(c.IdleTimeout)},
+		{"ShutdownTimeout", int64(c.ShutdownTimeout)},
+		{"ConnQueueTimeout", int64(c.ConnQueueTimeout)},
+		{"MaxHeaderBytes", int64(c.MaxHeaderBytes)},
+		{"MaxConns", int64(c.MaxConns)},
+		{"MaxConnsPerIP", int64(c.MaxConnsPerIP)},
+	}
+	if c.HSTS != nil {
+		nonNegative = append(nonNegative, field{"HSTS.MaxAge", int64(c.HSTS.MaxAge)})
+	}
+	if c.ProxyProtocol != nil {
+		nonNegative = append(nonNegative, field{"ProxyProtocol.HeaderTimeout", int64(c.ProxyProtocol.HeaderTimeout)})
+	}
+	if c.ProxyProtocol != nil && len(c.ProxyProtocol.TrustedCIDRs) == 0 && !c.ProxyProtocol.TrustAll {
+		errs = append(errs, errors.New("httpx: ProxyProtocol requires TrustedCIDRs or TrustAll"))
+	}
+	for _, v := range nonNegative {
+		if v.value < 0 {
+			errs = append(errs, fmt.Errorf("httpx: %s must not be negative, got %d", v.name, v.value))
+		}
+	}
+
+	switch c.ConnLimitMode {
+	case ConnLimitQueue:
+		if c.ConnQueueTimeout == 0 {
+			errs = append(errs, errors.New("httpx: ConnLimitMode queue requires ConnQueueTimeout"))
+		}
+	case ConnLimitReject:
+		if c.ConnQueueTimeout > 0 {
+			errs = append(errs, errors.New("httpx: ConnQueueTimeout requires ConnLimitMode queue"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("httpx: unknown ConnLimitMode %q", c.ConnLimitMode))
+	}
+
+	if c.WriteTimeout > 0 && c.WriteTimeout < c.ReadHeaderTimeout {
+		errs = append(errs, fmt.Errorf("httpx: WriteTimeout (%s) is shorter than ReadHeaderTimeout (%s)",
+			c.WriteTimeout, c.ReadHeaderTimeout))
+	}
+
+	if tc := c.TLSConfig; tc != nil && c.ACME == nil && len(tc.Certificates) == 0 && tc.GetCertificate == nil && tc.GetConfigForClient == nil {
+		errs = append(errs, errors.New("httpx: TLSConfig has no certificates, GetCertificate or GetConfigForClient"))
+	}
+
+	if c.ACME != nil && len(c.ACME.Hosts) == 0 {
+		errs = append(errs, errors.New("httpx: ACME requires Hosts"))
+	}
+
+	if c.RedirectAddr != "" {
+		if !c.tlsEnabled() {
+			errs = append(errs, errors.New("httpx: RedirectAddr requires TLSConfig or ACME"))
+		}
+		if err := validateAddr(c.RedirectAddr); err != nil {
+			errs = append(errs, fmt.Errorf("httpx: RedirectAddr: %w", err))
+		}
+		if !Is3xx(c.RedirectStatus) {
+			errs = append(errs, fmt.Errorf("httpx: RedirectStatus must be 3xx, got %d", c.RedirectStatus))
+		}
+	}
+	if c.HSTS != nil && !c.tlsEnabled() {
+		errs = append(errs, errors.New("httpx: HSTS requires TLSConfig or ACME"))
+	}
+
+	protocols := c.protocols()
+	if !protocols.HTTP1() && !protocols.HTTP2() && !protocols.UnencryptedHTTP2() {
+		errs = append(errs, errors.New("httpx: no protocols enabled"))
+	}
+
+	if c.HeartbeatPath != "" && !strings.HasPrefix(c.HeartbeatPath, "/") {
+		errs = append(errs, fmt.Errorf("httpx: HeartbeatPath must start with '/', got %q", c.HeartbeatPath))
+	}
+	return errors.Join(errs...)
+}
+
+func validateAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("httpx: malformed Addr %q: %w", addr, err)
+	}
+	if _, err := net.LookupPort("tcp", port); err != nil {
+		return fmt.Errorf("httpx: malformed Addr %q: %w", addr, err)
+	}
 	return nil
 }
 
+func (c *ServerConfig) tlsEnabled() bool {
+	return c.TLSConfig != nil || c.ACME != nil
+}
+
+// protocols returns the effective set of protocols.
+func (c *ServerConfig) protocols() http.Protocols {
+	protocols := defaultProtocols()
+	if c.Protocols != nil {
+		protocols = *c.Protocols
+	}
+	if c.NoHTTP2 {
+		protocols.SetHTTP2(false)
+		protocols.SetUnencryptedHTTP2(false)
+	}
+	return protocols
+}
+
 // NewServer returns a new Server.
 func NewServer(config *ServerConfig) (*Server, error) {
 	if err := config.Validate(); err != nil {
@@ -62,17 +271,55 @@ func NewServer(config *ServerConfig) (*Server, error) {
 	s := &Server{
 		srv: &http.Server{
 			Addr:              config.Addr,
-			Handler:           config.Handler,
 			ReadTimeout:       config.ReadTimeout,
 			ReadHeaderTimeout: config.ReadHeaderTimeout,
 			WriteTimeout:      config.WriteTimeout,
 			IdleTimeout:       config.IdleTimeout,
 			MaxHeaderBytes:    config.MaxHeaderBytes,
+			TLSConfig:         config.TLSConfig,
+			HTTP2:             config.HTTP2,
 		},
+		cfg: config,
+	}
+
+	if config.MaxConns > 0 || config.MaxConnsPerIP > 0 {
+		s.limiter = newConnLimiter(config)
 	}
 
-	if config.NoHTTP2 {
-		s.srv.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	protocols := config.protocols()
+	s.srv.Protocols = &protocols
+
+	if config.Logger != nil {
+		s.srv.ErrorLog = log.New(&serverLogWriter{logger: config.Logger}, "", 0)
+	}
+	s.srv.ConnContext = s.connContext
+	s.srv.ConnState = s.connState
+
+	if config.ACME != nil {
+		m, err := NewACMEManager(*config.ACME)
+		if err != nil {
+			return nil, err
+		}
+		if config.Logger != nil {
+			m.logger = config.Logger
+		}
+
+		tlsConfig := m.TLSConfig()
+		if config.TLSConfig != nil {
+			tlsConfig = config.TLSConfig.Clone()
+			tlsConfig.GetCertificate = m.GetCertificate
+			if !slices.Contains(tlsConfig.NextProtos, acmeALPNProto) {
+				tlsConfig.NextProtos = append(tlsConfig.NextProtos, acmeALPNProto)
+			}
+		}
+		s.srv.TLSConfig = tlsConfig
+		s.acme = m
+	}
+
+	s.setHandler(config.Handler)
+
+	if config.RedirectAddr != "" {
+		s.redirect = s.newRedirectServer()
 	}
 	return s, nil
 }
@@ -80,38 +327,181 @@ func NewServer(config *ServerConfig) (*Server, error) {
 // Start the server with a given handler.
 // Same as Run but allows to set handler later.
 func (s *Server) Start(ctx context.Context, h http.Handler) error {
-	s.srv.Handler = h
+	s.setHandler(h)
 	return s.Run(ctx)
 }
 
+func (s *Server) setHandler(h http.Handler) {
+	if h != nil && s.cfg.HSTS != nil {
+		h = hstsHandler(s.cfg.HSTS, h)
+	}
+	s.srv.Handler = h
+}
+
 // Run starts the server.
 func (s *Server) Run(ctx context.Context) error {
 	if s.srv.Handler == nil {
 		panic("handler is nil")
 	}
 
-	s.srv.BaseContext = func(net.Listener) context.Context {
+	for _, fn := range s.onStart {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+	}
+
+	baseContext := func(net.Listener) context.Context {
 		return ctx
 	}
+	s.srv.BaseContext = baseContext
+
+	ln, err := s.listen(s.srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	var redirectLn net.Listener
+	if s.redirect != nil {
+		s.redirect.BaseContext = baseContext
+
+		// limits and PROXY protocol are for the main listener only.
+		redirectLn, err = net.Listen("tcp", s.redirect.Addr)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
 
-	errCh := make(chan error)
+	defer s.runShutdownHooks()
+
+	errCh := make(chan error, 2)
 	go func() {
-		errCh <- s.srv.ListenAndServe()
+		errCh <- s.serve(ln)
 	}()
+	if s.redirect != nil {
+		go func() {
+			errCh <- s.redirect.Serve(redirectLn)
+		}()
+	}
+
+	if s.acme != nil {
+		go s.acme.renewLoop(ctx)
+	}
+
+	for _, fn := range s.onReady {
+		fn(ctx)
+	}
 
 	select {
 	case <-ctx.Done():
 		return s.shutdown()
 
 	case err := <-errCh:
+		// stop the companion server too.
+		s.shutdown()
 		return err
 	}
 }
 
+// OnStart registers a function called by Run before the server starts listening.
+// If the function returns an error the server is not started and Run returns that error.
+func (s *Server) OnStart(fn func(ctx context.Context) error) {
+	s.onStart = append(s.onStart, fn)
+}
+
+// OnReady registers a function called by Run once the server is listening.
+// See [Server.Addr] to get the listening address.
+func (s *Server) OnReady(fn func(ctx context.Context)) {
+	s.onReady = append(s.onReady, fn)
+}
+
+// OnShutdown registers a function called after the server is stopped.
+// Context passed to the function is limited by ShutdownTimeout.
+func (s *Server) OnShutdown(fn func(ctx context.Context)) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// Addr returns the listening address or nil if the server is not started.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// ConnLimitStats returns connection limiter counters.
+// Returns zero stats if MaxConns and MaxConnsPerIP are not set.
+func (s *Server) ConnLimitStats() ConnLimitStats {
+	if s.limiter == nil {
+		return ConnLimitStats{}
+	}
+	return s.limiter.stats()
+}
+
+func (s *Server) serve(ln net.Listener) error {
+	if s.srv.TLSConfig != nil {
+		// certificates are taken from TLSConfig.
+		return s.srv.ServeTLS(ln, "", "")
+	}
+	return s.srv.Serve(ln)
+}
+
+func (s *Server) listen(addr string) (net.Listener, error) {
+	if addr == "" {
+		addr = ":http"
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cfg.ProxyProtocol != nil {
+		ln = newProxyListener(ln, s.cfg.ProxyProtocol)
+	}
+	if s.limiter != nil {
+		ln = &limitListener{Listener: ln, limiter: s.limiter}
+	}
+	return ln, nil
+}
+
 func (s *Server) shutdown() error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	if s.redirect == nil {
+		return s.srv.Shutdown(ctx)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.redirect.Shutdown(ctx)
+	}()
+	err := s.srv.Shutdown(ctx)
+	return errors.Join(err, <-errCh)
+}
+
+func (s *Server) runShutdownHooks() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
 	defer cancel()
-	return s.srv.Shutdown(ctx)
+
+	for _, fn := range s.onShutdown {
+		fn(ctx)
+	}
+}
+
+func defaultProtocols() http.Protocols {
+	var p http.Protocols
+	p.SetHTTP1(true)
+	p.SetHTTP2(true)
+	return p
 }
 
 func defaultHeartbeatHandler(w http.ResponseWriter, r *http.Request) {
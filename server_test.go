@@ -2,8 +2,12 @@ package httpx
 
 import (
 	"context"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
+	"slices"
 	"testing"
 )
 
@@ -16,14 +20,13 @@ func TestServer(t *testing.T) {
 			w.Header().Del("Date")
 			w.Write([]byte("test"))
 		}),
+		Logger: slog.New(slog.DiscardHandler),
 	}
 	srv, err := NewServer(cfg)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	srv.srv.ErrorLog = nil
-
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		srv.Run(ctx)
@@ -45,3 +48,152 @@ func TestServer(t *testing.T) {
 		t.Fatal("should fail")
 	}
 }
+
+func TestServerH2C(t *testing.T) {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	srv, err := NewServer(&ServerConfig{
+		Addr:      "127.0.0.1:0",
+		Protocols: &protocols,
+		HTTP2:     &http.HTTP2Config{MaxConcurrentStreams: 10},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Proto))
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := srv.listen(srv.srv.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.serve(ln)
+	defer srv.shutdown()
+
+	var h2c http.Protocols
+	h2c.SetUnencryptedHTTP2(true)
+
+	testCases := []struct {
+		client *http.Client
+		want   string
+	}{
+		{client: NewClient(), want: "HTTP/1.1"},
+		{client: NewPooledClient(WithProtocols(h2c)), want: "HTTP/2.0"},
+	}
+
+	for _, tc := range testCases {
+		resp, err := tc.client.Get("http://" + ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if string(body) != tc.want {
+			t.Errorf("have %q, want %q", body, tc.want)
+		}
+	}
+}
+
+func TestRunGroup(t *testing.T) {
+	srv, err := NewServer(&ServerConfig{
+		Addr:    "127.0.0.1:0",
+		Handler: http.HandlerFunc(NoopHandler),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hooks []string
+	srv.OnStart(func(ctx context.Context) error {
+		hooks = append(hooks, "start")
+		return nil
+	})
+	srv.OnShutdown(func(ctx context.Context) {
+		hooks = append(hooks, "shutdown")
+	})
+
+	ready := make(chan struct{})
+	srv.OnReady(func(ctx context.Context) {
+		hooks = append(hooks, "ready")
+		close(ready)
+	})
+
+	errFail := errors.New("fail")
+
+	var g RunGroup
+	g.AddServer(srv)
+	g.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Add(func(ctx context.Context) error {
+		<-ready
+
+		resp, err := http.Get("http://" + srv.Addr().String())
+		if err != nil {
+			return err
+		}
+		DiscardResponseBody(resp)
+		return errFail
+	})
+
+	err = g.Run(context.Background())
+	if !errors.Is(err, errFail) {
+		t.Fatalf("have %v, want %v", err, errFail)
+	}
+
+	want := []string{"start", "ready", "shutdown"}
+	if !slices.Equal(hooks, want) {
+		t.Errorf("have %v, want %v", hooks, want)
+	}
+}
+
+func TestServerConnInfo(t *testing.T) {
+	var srv *Server
+	var err error
+
+	srv, err = NewServer(&ServerConfig{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ci := ConnInfoFromContext(r.Context())
+			if ci == nil {
+				t.Error("no conn info")
+				return
+			}
+			if ci.ID == 0 || ci.AcceptedAt.IsZero() || ci.RemoteAddr().String() != r.RemoteAddr {
+				t.Errorf("unexpected conn info: %+v", ci)
+			}
+			if ci.State() != http.StateActive || ci.TLS() != nil {
+				t.Errorf("unexpected conn state: %v", ci.State())
+			}
+
+			stats := srv.ConnStats()
+			if stats.Active != 1 || stats.Accepted != 1 {
+				t.Errorf("unexpected stats: %+v", stats)
+			}
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := srv.listen(srv.srv.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.serve(ln)
+	defer srv.shutdown()
+
+	client := NewPooledClient()
+	resp, err := client.Get("http://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	DiscardResponseBody(resp)
+
+	client.CloseIdleConnections()
+}
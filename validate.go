@@ -0,0 +1,365 @@
+package httpx
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Validate a struct by `validate` tags with comma-separated rules:
+//
+//	type CreateUser struct {
+//		Name  string   `json:"name" validate:"required,min=2,max=64"`
+//		Email string   `json:"email" validate:"required,email"`
+//		Role  string   `json:"role" validate:"oneof=admin user"`
+//		Site  string   `json:"site" validate:"url"`
+//		Code  string   `json:"code" validate:"len=6,regex=^[0-9]+$"`
+//		Tags  []string `json:"tags" validate:"max=10"`
+//	}
+//
+// Rules:
+//   - required - value is not zero, slices and maps are not empty.
+//   - min=N, max=N - bounds of a number or length of a string, slice or map.
+//   - len=N - exact length of a string, slice or map.
+//   - oneof=a b c - value is one of space-separated values.
+//   - email, url - value is an email address or an absolute URL.
+//   - regex=expr - value matches the expression, must be the last rule.
+//
+// Empty strings, slices and maps and nil pointers are checked only by required rule,
+// numbers and other values are checked by all the rules.
+// Nested structs, pointers to them and slices of structs are validated too.
+//
+// All the problems are reported in [Error.Fields] with 400 Bad Request code.
+// Field names are taken from json or [Bind] tags, nested fields have paths like "items[0].name".
+// Tags are parsed once per type, malformed tags are reported as a plain error.
+func Validate(v any) error {
+	var errs []FieldError
+	if err := validateValue(reflect.ValueOf(v), "", &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return &Error{
+			Code:    http.StatusBadRequest,
+			Message: "validation failed",
+			Fields:  errs,
+		}
+	}
+	return nil
+}
+
+func validateValue(v reflect.Value, path string, errs *[]FieldError) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return validateStruct(v, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := validateValue(v.Index(i), path+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, path string, errs *[]FieldError) error {
+	t := v.Type()
+	rules, err := structRules(t)
+	if err != nil {
+		return err
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		in, name := fieldBinding(f)
+		if in == "" {
+			jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if jsonName == "-" {
+				continue
+			}
+			if f.Anonymous && jsonName == "" {
+				if err := validateValue(v.Field(i), path, errs); err != nil {
+					return err
+				}
+				continue
+			}
+			name = cmp.Or(jsonName, f.Name)
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+
+		fv := v.Field(i)
+		if rules[i] != nil {
+			if msg := rules[i].check(fv); msg != "" {
+				*errs = append(*errs, FieldError{Field: name, In: in, Message: msg})
+				continue
+			}
+		}
+		if err := validateValue(fv, name, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldRules are parsed rules of a field.
+type fieldRules struct {
+	required bool
+	rules    []validateRule
+}
+
+type validateRule struct {
+	name   string
+	arg    string
+	n      float64        // min, max and len.
+	values []string       // oneof.
+	re     *regexp.Regexp // regex.
+}
+
+type cachedRules struct {
+	rules []*fieldRules // by field index, nil without rules.
+	err   error
+}
+
+var rulesCache sync.Map // map[reflect.Type]cachedRules
+
+// structRules returns parsed rules of the struct fields.
+func structRules(t reflect.Type) ([]*fieldRules, error) {
+	if cached, ok := rulesCache.Load(t); ok {
+		c := cached.(cachedRules)
+		return c.rules, c.err
+	}
+
+	c := cachedRules{rules: make([]*fieldRules, t.NumField())}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("validate")
+		if tag == "" || !f.IsExported() {
+			continue
+		}
+
+		rules, err := parseRules(f.Type, tag)
+		if err != nil {
+			c = cachedRules{err: fmt.Errorf("httpx: invalid validate tag of %s.%s: %w", t, f.Name, err)}
+			break
+		}
+		c.rules[i] = rules
+	}
+
+	rulesCache.Store(t, c)
+	return c.rules, c.err
+}
+
+// checkValidateTags parses validate tags of a type and the nested types.
+func checkValidateTags(t reflect.Type) error {
+	return walkStructs(t, map[reflect.Type]bool{}, func(t reflect.Type) error {
+		_, err := structRules(t)
+		return err
+	})
+}
+
+func walkStructs(t reflect.Type, seen map[reflect.Type]bool, fn func(reflect.Type) error) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	if err := fn(t); err != nil {
+		return err
+	}
+	for i := range t.NumField() {
+		if err := walkStructs(t.Field(i).Type, seen, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseRules(t reflect.Type, tag string) (*fieldRules, error) {
+	t = indirect(t)
+	fr := &fieldRules{}
+
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		if name == "regex" {
+			arg = tag[strings.Index(tag, "regex=")+len("regex="):]
+		}
+
+		r := validateRule{name: name, arg: arg}
+		switch name {
+		case "":
+			continue
+
+		case "required":
+			fr.required = true
+			continue
+
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s rule argument %q", name, arg)
+			}
+			if !hasBound(t) {
+				return nil, fmt.Errorf("%s rule is not supported for %s", name, t)
+			}
+			r.n = n
+
+		case "oneof":
+			r.values = strings.Fields(arg)
+
+		case "email", "url", "regex":
+			if t.Kind() != reflect.String {
+				return nil, fmt.Errorf("%s rule is not supported for %s", name, t)
+			}
+			if name == "regex" {
+				re, err := regexp.Compile(arg)
+				if err != nil {
+					return nil, err
+				}
+				r.re = re
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+
+		fr.rules = append(fr.rules, r)
+		if name == "regex" {
+			break
+		}
+	}
+	return fr, nil
+}
+
+// check returns a message for the first failed rule.
+func (fr *fieldRules) check(v reflect.Value) string {
+	empty := isEmptyValue(v)
+	if fr.required && (empty || v.IsZero()) {
+		return "is required"
+	}
+	if empty {
+		return ""
+	}
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	for _, r := range fr.rules {
+		if msg := r.check(v); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// isEmptyValue reports whether a value is a nil pointer or an empty string, slice or map.
+func isEmptyValue(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func (r validateRule) check(v reflect.Value) string {
+	switch r.name {
+	case "min", "max", "len":
+		return checkBound(v, r.name, r.n, r.arg)
+
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		if slices.Contains(r.values, s) {
+			return ""
+		}
+		return "must be one of: " + strings.Join(r.values, ", ")
+
+	case "email":
+		addr, err := mail.ParseAddress(v.String())
+		if err != nil || addr.Address != v.String() {
+			return "must be a valid email address"
+		}
+
+	case "url":
+		u, err := url.Parse(v.String())
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a valid URL"
+		}
+
+	case "regex":
+		if !r.re.MatchString(v.String()) {
+			return "must match " + r.arg
+		}
+	}
+	return ""
+}
+
+// hasBound reports whether min, max and len rules are supported for a type.
+func hasBound(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+	return false
+}
+
+func checkBound(v reflect.Value, rule string, n float64, arg string) string {
+	var value float64
+	var unit string
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	case reflect.String:
+		value, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		value, unit = float64(v.Len()), " items"
+	}
+
+	switch {
+	case rule == "min" && value < n:
+		return "must be at least " + arg + unit
+	case rule == "max" && value > n:
+		return "must be at most " + arg + unit
+	case rule == "len" && value != n:
+		return "must be exactly " + arg + unit
+	}
+	return ""
+}
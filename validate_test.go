@@ -0,0 +1,136 @@
+package httpx
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type Item struct {
+		SKU   string `json:"sku" validate:"required,regex=^[A-Z]{2},[0-9]+$"`
+		Count int    `json:"count" validate:"min=1,max=10"`
+	}
+	type Address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type Order struct {
+		ID      int64    `json:"-" path:"id" validate:"min=1"`
+		Email   string   `json:"email" validate:"required,email"`
+		Site    string   `json:"site,omitempty" validate:"url"`
+		Status  string   `json:"status" validate:"oneof=new paid"`
+		Code    string   `json:"code" validate:"len=4"`
+		Note    string   `json:"note" validate:"max=5"`
+		Tags    []string `validate:"required,max=2"`
+		Items   []Item   `json:"items"`
+		Address *Address `json:"address"`
+	}
+
+	valid := Order{
+		ID:      1,
+		Email:   "ann@example.com",
+		Site:    "https://example.com",
+		Status:  "paid",
+		Code:    "ab12",
+		Note:    "héllo",
+		Tags:    []string{"a"},
+		Items:   []Item{{SKU: "AB,12", Count: 1}},
+		Address: &Address{City: "Berlin"},
+	}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("valid order: %v", err)
+	}
+
+	invalid := Order{
+		Email:   "Ann <ann@example.com>",
+		Site:    "example.com",
+		Status:  "lost",
+		Code:    "abc",
+		Note:    "too long",
+		Items:   []Item{{SKU: "AB,12", Count: 0}, {SKU: "ab", Count: 11}},
+		Address: &Address{},
+	}
+	err := Validate(&invalid)
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("want *Error; have %#v", err)
+	}
+
+	want := []FieldError{
+		{Field: "id", In: "path", Message: "must be at least 1"},
+		{Field: "email", Message: "must be a valid email address"},
+		{Field: "site", Message: "must be a valid URL"},
+		{Field: "status", Message: "must be one of: new, paid"},
+		{Field: "code", Message: "must be exactly 4 characters"},
+		{Field: "note", Message: "must be at most 5 characters"},
+		{Field: "Tags", Message: "is required"},
+		{Field: "items[0].count", Message: "must be at least 1"},
+		{Field: "items[1].sku", Message: "must match ^[A-Z]{2},[0-9]+$"},
+		{Field: "items[1].count", Message: "must be at most 10"},
+		{Field: "address.city", Message: "is required"},
+	}
+	if e.Code != http.StatusBadRequest || !reflect.DeepEqual(e.Fields, want) {
+		t.Errorf("want %+v\nhave %+v", want, e.Fields)
+	}
+}
+
+func TestValidateEmpty(t *testing.T) {
+	type Filter struct {
+		Status string   `json:"status" validate:"oneof=new paid"`
+		Site   *string  `json:"site" validate:"url"`
+		Tags   []string `json:"tags" validate:"min=1"`
+		Limit  *int     `json:"limit" validate:"min=1"`
+		Offset int      `json:"offset" validate:"max=100"`
+	}
+
+	if err := Validate(Filter{}); err != nil {
+		t.Errorf("empty values must be skipped: %v", err)
+	}
+
+	zero := 0
+	err := Validate(Filter{Limit: &zero})
+	if e, ok := err.(*Error); !ok || len(e.Fields) != 1 || e.Fields[0].Field != "limit" {
+		t.Errorf("want limit error; have %v", err)
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	tests := []struct {
+		Value   any
+		WantErr string
+	}{
+		{struct {
+			N int `validate:"min=abc"`
+		}{}, `invalid min rule argument "abc"`},
+		{struct {
+			B bool `validate:"max=1"`
+		}{}, "max rule is not supported for bool"},
+		{struct {
+			N int `validate:"email"`
+		}{}, "email rule is not supported for int"},
+		{struct {
+			S string `validate:"regex=["`
+		}{}, "missing closing ]"},
+		{struct {
+			S string `validate:"uuid"`
+		}{}, `unknown rule "uuid"`},
+		{struct {
+			Items []struct {
+				S string `validate:"nope"`
+			}
+		}{Items: make([]struct {
+			S string `validate:"nope"`
+		}, 1)}, `unknown rule "nope"`},
+	}
+
+	for _, test := range tests {
+		err := Validate(test.Value)
+		if _, ok := err.(*Error); ok || err == nil || !strings.Contains(err.Error(), test.WantErr) {
+			t.Errorf("%T: want error %q; have %v", test.Value, test.WantErr, err)
+		}
+		if err := checkValidateTags(reflect.TypeOf(test.Value)); err == nil || !strings.Contains(err.Error(), test.WantErr) {
+			t.Errorf("%T: check want error %q; have %v", test.Value, test.WantErr, err)
+		}
+	}
+}
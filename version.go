@@ -0,0 +1,219 @@
+package httpx
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionConfig configures how API version is selected, see [Router.Versioning].
+type VersionConfig struct {
+	// Header with a version like "2" or "v2". Default is "API-Version".
+	Header string
+	// Vendor enables versions in Accept header like application/vnd.{Vendor}.v2+json.
+	Vendor string
+	// PathPrefix enables versions in path like /v2/users.
+	// Prefix is removed from the path before routing.
+	PathPrefix bool
+	// Default version for requests without a version.
+	// If empty, such requests are served by unversioned routes only.
+	Default string
+}
+
+// Version of API registered with [Router.Version].
+type Version struct {
+	// Name like "1" or "2", leading "v" is ignored.
+	Name string
+	// Deprecated is a time of deprecation, sent in Deprecation header (RFC 9745).
+	Deprecated time.Time
+	// Sunset is a time when the version will be removed, sent in Sunset header (RFC 8594).
+	Sunset time.Time
+}
+
+type versioning struct {
+	cfg      VersionConfig
+	versions []*versionRoute
+}
+
+type versionRoute struct {
+	version Version
+	router  *Router
+}
+
+type versionKey struct{}
+
+// Versioning sets how API version is selected for routes registered with [Router.Version].
+// Version is taken from the path prefix, the header, the Accept header
+// or the default version in this order.
+// Versioning is shared with subrouters and must be set on the root router.
+func (r *Router) Versioning(cfg VersionConfig) {
+	if r.isSubRouter {
+		panic("httpx: versioning must be set on the root router")
+	}
+	cfg.Default = normalizeVersion(cfg.Default)
+	// media types are compared in lower case, see mime.ParseMediaType.
+	cfg.Vendor = strings.ToLower(cfg.Vendor)
+	r.versioning.cfg = cfg
+}
+
+// Version creates a router for the API version.
+// Routes of a version take precedence over unversioned routes of the router.
+// Unknown versions requested explicitly get 400 Bad Request.
+// Deprecation and Sunset headers are set for deprecated versions.
+//
+//	r.Version(httpx.Version{Name: "1", Deprecated: deprecatedAt}, func(r *httpx.Router) {
+//		r.HandleFunc("GET /users/{id}", getUserV1)
+//	})
+//	r.Version(httpx.Version{Name: "2"}, func(r *httpx.Router) {
+//		r.HandleFunc("GET /users/{id}", getUserV2)
+//	})
+//
+// Version router has its own middlewares, not found and error handling are shared.
+// Prefix of a subrouter created by [Router.Route] is added to the version router patterns.
+func (r *Router) Version(v Version, fn func(r *Router)) {
+	v.Name = normalizeVersion(v.Name)
+	if v.Name == "" {
+		panic("httpx: version name is required")
+	}
+
+	vr := &versionRoute{version: v, router: NewRouter()}
+	vr.router.config = r.config
+	vr.router.prefix = r.prefix
+	vr.router.globalMw = slices.Clone(r.routeMw)
+	fn(vr.router)
+
+	r.versioning.versions = append(r.versioning.versions, vr)
+}
+
+// APIVersion returns the API version of the request selected by [Router.Version].
+func APIVersion(r *http.Request) string {
+	v, _ := r.Context().Value(versionKey{}).(string)
+	return v
+}
+
+func (r *Router) serveVersion(w http.ResponseWriter, req *http.Request) bool {
+	vs := r.versioning
+	if len(vs.versions) == 0 {
+		return false
+	}
+
+	name, prefixed := vs.pathVersion(req)
+	if !prefixed {
+		name = vs.requestVersion(req)
+	}
+
+	explicit := name != ""
+	name = cmp.Or(name, vs.cfg.Default)
+	if name == "" {
+		return false
+	}
+
+	i := slices.IndexFunc(vs.versions, func(vr *versionRoute) bool {
+		return vr.version.Name == name
+	})
+	if i == -1 {
+		if !explicit {
+			return false
+		}
+		vs.vary(w)
+		r.config.statusError(w, req, http.StatusBadRequest, errors.New("unsupported API version "+name))
+		return true
+	}
+	vr := vs.versions[i]
+
+	ctx := context.WithValue(req.Context(), versionKey{}, name)
+	if prefixed {
+		// report prefix in the pattern like for mounted routers.
+		req = req.WithContext(ctx)
+		req.Pattern = "/v" + name + "/"
+		if outer, ok := ctx.Value(mountKey{}).(string); ok {
+			req.Pattern = joinPattern(outer, req.Pattern)
+		}
+		req = mountRequest(req, 1, nil)
+	} else {
+		// fall back to unversioned routes when the version has no such path.
+		if !vr.router.hasRoute(req) {
+			return false
+		}
+		vs.vary(w)
+		req = req.WithContext(ctx)
+	}
+
+	if !vr.version.Deprecated.IsZero() {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(vr.version.Deprecated.Unix(), 10))
+	}
+	if !vr.version.Sunset.IsZero() {
+		w.Header().Set("Sunset", vr.version.Sunset.UTC().Format(http.TimeFormat))
+	}
+	vr.router.ServeHTTP(w, req)
+	return true
+}
+
+// pathVersion returns version from the path prefix like /v2/.
+func (vs *versioning) pathVersion(r *http.Request) (string, bool) {
+	if !vs.cfg.PathPrefix {
+		return "", false
+	}
+
+	seg, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(seg) < 2 || seg[0] != 'v' || !slices.ContainsFunc(vs.versions, func(vr *versionRoute) bool {
+		return vr.version.Name == seg[1:]
+	}) {
+		return "", false
+	}
+	return seg[1:], true
+}
+
+// requestVersion returns version from the header or Accept header.
+func (vs *versioning) requestVersion(r *http.Request) string {
+	if v := r.Header.Get(vs.header()); v != "" {
+		return normalizeVersion(v)
+	}
+	if vs.cfg.Vendor == "" {
+		return ""
+	}
+
+	prefix := "application/vnd." + vs.cfg.Vendor + ".v"
+	for _, accept := range r.Header.Values("Accept") {
+		for part := range strings.SplitSeq(accept, ",") {
+			typ, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			v, ok := strings.CutPrefix(typ, prefix)
+			if !ok {
+				continue
+			}
+			if v, ok = strings.CutSuffix(v, "+json"); ok && v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// vary adds headers used to select a version to Vary.
+func (vs *versioning) vary(w http.ResponseWriter) {
+	w.Header().Add("Vary", vs.header())
+	if vs.cfg.Vendor != "" {
+		w.Header().Add("Vary", "Accept")
+	}
+}
+
+func (vs *versioning) header() string {
+	return cmp.Or(vs.cfg.Header, "API-Version")
+}
+
+func normalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) > 1 && (v[0] == 'v' || v[0] == 'V') {
+		v = v[1:]
+	}
+	return v
+}
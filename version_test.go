@@ -0,0 +1,154 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRouterVersion(t *testing.T) {
+	deprecated := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	hf := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s|%s|%s", name, r.Pattern, APIVersion(r))
+		}
+	}
+
+	r := NewRouter()
+	r.Versioning(VersionConfig{Vendor: "Acme", PathPrefix: true, Default: "v2"})
+	r.HandleFunc("GET /users/{id}", hf("base"))
+	r.HandleFunc("GET /health", hf("base"))
+
+	r.Version(Version{Name: "v1", Deprecated: deprecated, Sunset: sunset}, func(r *Router) {
+		r.HandleFunc("GET /users/{id}", hf("v1"))
+	})
+	r.Version(Version{Name: "2"}, func(r *Router) {
+		r.HandleFunc("GET /users/{id}", hf("v2"))
+	})
+
+	tests := []struct {
+		URL        string
+		Header     string
+		Accept     string
+		WantStatus int
+		WantBody   string
+		WantDepr   string
+		WantVary   string
+	}{
+		{"/users/1", "", "", http.StatusOK, "v2|GET /users/{id}|2", "", "API-Version, Accept"},
+		{"/v1/users/1", "", "", http.StatusOK, "v1|GET /v1/users/{id}|1", "@1735689600", ""},
+		{"/v2/users/1", "", "", http.StatusOK, "v2|GET /v2/users/{id}|2", "", ""},
+		{"/users/1", "v1", "", http.StatusOK, "v1|GET /users/{id}|1", "@1735689600", "API-Version, Accept"},
+		{"/users/1", "", "application/vnd.acme.v1+json", http.StatusOK, "v1|GET /users/{id}|1", "@1735689600", "API-Version, Accept"},
+		{"/health", "1", "", http.StatusOK, "base|GET /health|", "", ""},
+		{"/users/1", "3", "", http.StatusBadRequest, "", "", "API-Version, Accept"},
+		{"/v3/users/1", "", "", http.StatusNotFound, "", "", ""},
+		{"/v1/nope", "", "", http.StatusNotFound, "", "@1735689600", ""},
+	}
+
+	for _, test := range tests {
+		rq := httptest.NewRequest("GET", test.URL, nil)
+		if test.Header != "" {
+			rq.Header.Set("API-Version", test.Header)
+		}
+		if test.Accept != "" {
+			rq.Header.Set("Accept", test.Accept)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, rq)
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s %q: status want %d; have %d", test.URL, test.Header, test.WantStatus, rr.Code)
+		}
+		if test.WantBody != "" && rr.Body.String() != test.WantBody {
+			t.Errorf("%s %q: body want %q; have %q", test.URL, test.Header, test.WantBody, rr.Body.String())
+		}
+		if depr := rr.Header().Get("Deprecation"); depr != test.WantDepr {
+			t.Errorf("%s %q: deprecation want %q; have %q", test.URL, test.Header, test.WantDepr, depr)
+		}
+		if vary := strings.Join(rr.Header().Values("Vary"), ", "); vary != test.WantVary {
+			t.Errorf("%s %q: vary want %q; have %q", test.URL, test.Header, test.WantVary, vary)
+		}
+		if test.WantDepr != "" && rr.Header().Get("Sunset") != "Thu, 01 Jan 2026 00:00:00 GMT" {
+			t.Errorf("%s %q: sunset have %q", test.URL, test.Header, rr.Header().Get("Sunset"))
+		}
+	}
+
+	var have []string
+	for _, route := range r.Routes() {
+		have = append(have, route.Version+":"+route.Pattern)
+	}
+	want := fmt.Sprint([]string{":GET /users/{id}", ":GET /health", "1:GET /users/{id}", "2:GET /users/{id}"})
+	if fmt.Sprint(have) != want {
+		t.Errorf("routes want %s; have %q", want, have)
+	}
+}
+
+func TestRouterVersionPrefix(t *testing.T) {
+	hf := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s|%s", name, r.Pattern)
+		}
+	}
+
+	r := NewRouter()
+	r.Route("/api", func(r *Router) {
+		r.HandleFunc("GET /users", hf("base"))
+		r.HandleFunc("POST /users", hf("base"))
+		r.Version(Version{Name: "2"}, func(r *Router) {
+			r.HandleFunc("GET /users", hf("v2"))
+		})
+	})
+
+	tests := []struct {
+		Method     string
+		URL        string
+		Header     string
+		WantStatus int
+		WantBody   string
+		WantAllow  string
+		WantVary   string
+	}{
+		{"GET", "/api/users", "2", http.StatusOK, "v2|GET /api/users", "", "API-Version"},
+		{"GET", "/api/users", "", http.StatusOK, "base|GET /api/users", "", ""},
+		{"POST", "/api/users", "2", http.StatusMethodNotAllowed, "", "GET, HEAD, OPTIONS", "API-Version"},
+		{"OPTIONS", "/api/users", "2", http.StatusNoContent, "", "GET, HEAD, OPTIONS", "API-Version"},
+		{"GET", "/users", "2", http.StatusNotFound, "", "", ""},
+	}
+
+	for _, test := range tests {
+		rq := httptest.NewRequest(test.Method, test.URL, nil)
+		if test.Header != "" {
+			rq.Header.Set("API-Version", test.Header)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, rq)
+
+		if rr.Code != test.WantStatus {
+			t.Errorf("%s %s %q: status want %d; have %d", test.Method, test.URL, test.Header, test.WantStatus, rr.Code)
+		}
+		if test.WantBody != "" && rr.Body.String() != test.WantBody {
+			t.Errorf("%s %s %q: body want %q; have %q", test.Method, test.URL, test.Header, test.WantBody, rr.Body.String())
+		}
+		if allow := rr.Header().Get("Allow"); allow != test.WantAllow {
+			t.Errorf("%s %s %q: allow want %q; have %q", test.Method, test.URL, test.Header, test.WantAllow, allow)
+		}
+		if vary := rr.Header().Get("Vary"); vary != test.WantVary {
+			t.Errorf("%s %s %q: vary want %q; have %q", test.Method, test.URL, test.Header, test.WantVary, vary)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("versioning of a subrouter must panic")
+		}
+	}()
+	r.Group(func(r *Router) {
+		r.Versioning(VersionConfig{Header: "X-Version"})
+	})
+}